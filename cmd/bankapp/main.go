@@ -2,32 +2,94 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	inhttp "hexagonal-bank/internal/adapters/in/http"
+	"hexagonal-bank/internal/adapters/in/worker"
 	"hexagonal-bank/internal/adapters/out/eventbus"
+	"hexagonal-bank/internal/adapters/out/fx"
 	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/adapters/out/postgres"
 	"hexagonal-bank/internal/adapters/out/stp"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/application/usecase"
+	"hexagonal-bank/internal/platform/circuitbreaker"
 	"hexagonal-bank/internal/platform/logging"
 )
 
 func main() {
+	// Stop background workers and the server on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Logger
 	applicationLogger := logging.NewStd()
 
-	// In-memory repository simulating a database
-	accountRepository := memory.NewAccountRepo()
+	// Accounts (also the unit of work), transfers, the double-entry journal and
+	// the transactional outbox: in memory, or in PostgreSQL with DATABASE_URL
+	stores, err := newStores(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		applicationLogger.Error("database", "err", err)
+		os.Exit(1)
+	}
+	defer stores.close()
+	accountRepository, transferRepository := stores.accounts, stores.transfers
+	ledgerRepository, outbox := stores.ledger, stores.outbox
+
+	// Idempotency-Key store for money-moving endpoints (keys kept for 24h)
+	idempotencyStore := memory.NewIdempotencyStore(24 * time.Hour)
 
 	// Local event bus (in-process) simulating a queue/broker
 	localEventBus := eventbus.NewLocalBus(applicationLogger)
 
-	// Fake STP client with retry/backoff + jitter
-	fakeSTPGateway := stp.NewFakeSTP(applicationLogger)
+	// Relay draining the outbox into the event bus (retries with backoff + jitter)
+	outboxRelay := worker.NewOutboxRelay(applicationLogger, outbox, localEventBus, time.Second)
+	go outboxRelay.Run(ctx)
+
+	// Shared secret STP signs its webhook notifications with
+	stpWebhookSecret := os.Getenv("STP_WEBHOOK_SECRET")
+
+	// STP: the fake (default) or the real JSON API, chosen with STP_GATEWAY
+	stpGateway, err := newSTPGateway(applicationLogger, stpWebhookSecret)
+	if err != nil {
+		applicationLogger.Error("STP gateway configuration", "err", err)
+		os.Exit(1)
+	}
+
+	// Circuit breaker in front of STP: after 5 failed calls in a row, fail fast
+	// for 30s, then let a trial call decide
+	paymentGateway := stp.NewCircuitBreaker(applicationLogger, stpGateway, circuitbreaker.Settings{
+		FailureThreshold: 5,
+		CoolDown:         30 * time.Second,
+	})
+
+	// Fake FX rate provider; quotes are valid for a minute
+	fxRateProvider := fx.NewFakeRateProvider(time.Minute)
+
+	// Inconsistencies between STP and our books, kept for manual review
+	inconsistencyLog := memory.NewInconsistencyLog()
+
+	// Worker driving accepted transfers through STP, off the HTTP request path
+	processTransfers := usecase.NewProcessTransfersUseCase(
+		accountRepository, accountRepository, transferRepository, transferRepository,
+		ledgerRepository, ledgerRepository, accountRepository, paymentGateway, paymentGateway, outbox, inconsistencyLog,
+	)
+	transferProcessor := worker.NewTransferProcessor(applicationLogger, processTransfers, 500*time.Millisecond)
+	go transferProcessor.Run(ctx)
 
 	// HTTP API wiring: inject implementations into ports
-	httpAPI := inhttp.NewAPI(applicationLogger, accountRepository, accountRepository, fakeSTPGateway, localEventBus)
+	httpAPI := inhttp.NewAPI(
+		applicationLogger, accountRepository, accountRepository, accountRepository, transferRepository, transferRepository,
+		ledgerRepository, ledgerRepository, ledgerRepository, accountRepository, outbox, inconsistencyLog, idempotencyStore,
+		fxRateProvider, paymentGateway, []byte(stpWebhookSecret),
+	)
 
 	httpServer := &http.Server{
 		Addr:              ":8080",
@@ -38,12 +100,115 @@ func main() {
 		IdleTimeout:       60 * time.Second,
 	}
 
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
 	applicationLogger.Info("HexBank API starting on :8080...")
-	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		applicationLogger.Error("server error", "err", err)
 		os.Exit(1)
 	}
 
-	// Graceful shutdown example (not used in this minimal main)
-	_ = context.Background()
+	// Give the relay a last chance to publish what is already committed
+	outboxRelay.DrainOnce(context.Background())
+	applicationLogger.Info("HexBank API stopped")
+}
+
+// stores are the repositories sharing one unit of work: accounts.Do makes the
+// writes of all four atomic.
+type stores struct {
+	accounts interface {
+		ports.AccountReader
+		ports.AccountWriter
+		ports.AccountQuery
+		ports.UnitOfWork
+	}
+	transfers interface {
+		ports.TransferReader
+		ports.TransferWriter
+	}
+	ledger interface {
+		ports.LedgerReader
+		ports.LedgerWriter
+		ports.TransactionReader
+	}
+	outbox interface {
+		ports.Outbox
+		ports.OutboxRelayStore
+	}
+	close func() error
+}
+
+// newStores keeps everything in memory when databaseURL is empty. Otherwise
+// it connects to PostgreSQL (lib/pq connection string or URL) and migrates
+// the schema first.
+func newStores(ctx context.Context, databaseURL string) (stores, error) {
+	if databaseURL == "" {
+		return stores{
+			accounts:  memory.NewAccountRepo(),
+			transfers: memory.NewTransferRepo(),
+			ledger:    memory.NewLedgerRepo(),
+			outbox:    memory.NewOutbox(),
+			close:     func() error { return nil },
+		}, nil
+	}
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return stores{}, err
+	}
+	if err := postgres.Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return stores{}, fmt.Errorf("migrate: %w", err)
+	}
+	return stores{
+		accounts:  postgres.NewAccountRepo(db),
+		transfers: postgres.NewTransferRepo(db),
+		ledger:    postgres.NewLedgerRepo(db),
+		outbox:    postgres.NewOutbox(db),
+		close:     db.Close,
+	}, nil
+}
+
+// newSTPGateway builds the PaymentGateway STP_GATEWAY asks for:
+//   - "fake" (default): FakeSTP with retry/backoff + jitter. With webhooks
+//     enabled it settles asynchronously, calling back our status webhook like
+//     real SPEI.
+//   - "http": the real STP JSON API, configured by STP_URL, STP_COMPANY,
+//     STP_PRIVATE_KEY_FILE (PEM, signs orders) and optionally
+//     STP_CLIENT_CERT_FILE/STP_CLIENT_KEY_FILE and STP_CA_FILE (TLS).
+func newSTPGateway(logger logging.Logger, webhookSecret string) (ports.PaymentGateway, error) {
+	switch mode := os.Getenv("STP_GATEWAY"); mode {
+	case "", "fake":
+		config := stp.DefaultFakeSTPConfig()
+		if webhookSecret != "" {
+			config.CallbackURL = "http://localhost:8080/webhooks/stp/status"
+			config.CallbackSecret = []byte(webhookSecret)
+			config.CallbackDelay = 2 * time.Second
+		} else {
+			logger.Warn("STP_WEBHOOK_SECRET not set: STP webhooks disabled, fake STP settles synchronously")
+		}
+		return stp.NewFakeSTP(logger, config), nil
+	case "http":
+		if webhookSecret == "" {
+			logger.Warn("STP_WEBHOOK_SECRET not set: outbound transfers will stay SENT, STP reports outcomes by webhook")
+		}
+		config := stp.DefaultClientConfig()
+		config.BaseURL, config.Company = os.Getenv("STP_URL"), os.Getenv("STP_COMPANY")
+		privateKey, err := stp.LoadPrivateKey(os.Getenv("STP_PRIVATE_KEY_FILE"))
+		if err != nil {
+			return nil, err
+		}
+		config.PrivateKey = privateKey
+		config.TLS, err = stp.LoadTLSConfig(os.Getenv("STP_CLIENT_CERT_FILE"), os.Getenv("STP_CLIENT_KEY_FILE"), os.Getenv("STP_CA_FILE"))
+		if err != nil {
+			return nil, err
+		}
+		return stp.NewClient(logger, config)
+	default:
+		return nil, fmt.Errorf("unknown STP_GATEWAY %q, want fake or http", mode)
+	}
 }
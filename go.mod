@@ -1,3 +1,5 @@
 module hexagonal-bank
 
 go 1.25.1
+
+require github.com/lib/pq v1.12.3
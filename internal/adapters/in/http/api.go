@@ -1,6 +1,7 @@
 package inhttp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"hexagonal-bank/internal/core/application/ports"
@@ -8,57 +9,131 @@ import (
 	"hexagonal-bank/internal/core/domain"
 	"hexagonal-bank/internal/platform/logging"
 	"hexagonal-bank/internal/shared/httpx"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Handlers are thin: translate HTTP <-> UseCase DTOs.
 
 type API struct {
 	logger               logging.Logger
+	idempotencyStore     ports.IdempotencyStore
 	openAccountUseCase   *usecase.OpenAccountUseCase
+	searchAccounts       *usecase.SearchAccountsUseCase
 	depositMoneyUseCase  *usecase.DepositMoneyUseCase
+	withdrawMoneyUseCase *usecase.WithdrawMoneyUseCase
+	freezeAccount        *usecase.FreezeAccountUseCase
+	unfreezeAccount      *usecase.UnfreezeAccountUseCase
+	closeAccount         *usecase.CloseAccountUseCase
 	transferMoneyUseCase *usecase.TransferMoneyUseCase
+	outboundTransfer     *usecase.SendOutboundTransferUseCase
+	getTransferUseCase   *usecase.GetTransferUseCase
+	listTransactions     *usecase.ListTransactionsUseCase
+	quoteExchangeRate    *usecase.QuoteExchangeRateUseCase
+	receiveIncoming      *usecase.ReceiveIncomingTransferUseCase
+	updateTransferStatus *usecase.UpdateTransferStatusUseCase
+	gatewayMonitor       ports.GatewayMonitor
+	stpWebhookSecret     []byte
 }
 
 func NewAPI(
 	logger logging.Logger,
 	accountReader ports.AccountReader,
 	accountWriter ports.AccountWriter,
-	paymentGateway ports.PaymentGateway,
-	eventPublisher ports.EventPublisher,
+	accountQuery ports.AccountQuery,
+	transferReader ports.TransferReader,
+	transferWriter ports.TransferWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	transactionReader ports.TransactionReader,
+	unitOfWork ports.UnitOfWork,
+	outbox ports.Outbox,
+	inconsistencies ports.InconsistencyRecorder,
+	idempotencyStore ports.IdempotencyStore,
+	fxRateProvider ports.FXRateProvider,
+	gatewayMonitor ports.GatewayMonitor,
+	stpWebhookSecret []byte, // empty disables the STP webhook
 ) *API {
 	return &API{
 		logger:               logger,
+		idempotencyStore:     idempotencyStore,
 		openAccountUseCase:   usecase.NewOpenAccountUseCase(accountWriter),
-		depositMoneyUseCase:  usecase.NewDepositMoneyUseCase(accountReader, accountWriter),
-		transferMoneyUseCase: usecase.NewTransferMoneyUseCase(accountReader, accountWriter, paymentGateway, eventPublisher),
+		searchAccounts:       usecase.NewSearchAccountsUseCase(accountQuery),
+		depositMoneyUseCase:  usecase.NewDepositMoneyUseCase(accountReader, accountWriter, ledgerReader, ledgerWriter, unitOfWork),
+		withdrawMoneyUseCase: usecase.NewWithdrawMoneyUseCase(accountReader, accountWriter, ledgerReader, ledgerWriter, unitOfWork),
+		freezeAccount:        usecase.NewFreezeAccountUseCase(accountReader, accountWriter),
+		unfreezeAccount:      usecase.NewUnfreezeAccountUseCase(accountReader, accountWriter),
+		closeAccount:         usecase.NewCloseAccountUseCase(accountReader, accountWriter),
+		transferMoneyUseCase: usecase.NewTransferMoneyUseCase(accountReader, accountWriter, transferWriter, ledgerReader, ledgerWriter, unitOfWork, outbox, fxRateProvider),
+		outboundTransfer:     usecase.NewSendOutboundTransferUseCase(accountReader, accountWriter, transferWriter, unitOfWork, gatewayMonitor),
+		getTransferUseCase:   usecase.NewGetTransferUseCase(transferReader),
+		listTransactions:     usecase.NewListTransactionsUseCase(accountReader, transactionReader),
+		quoteExchangeRate:    usecase.NewQuoteExchangeRateUseCase(fxRateProvider),
+		receiveIncoming:      usecase.NewReceiveIncomingTransferUseCase(accountReader, accountWriter, transferReader, transferWriter, ledgerReader, ledgerWriter, unitOfWork, outbox),
+		updateTransferStatus: usecase.NewUpdateTransferStatusUseCase(accountReader, accountWriter, transferReader, transferWriter, ledgerReader, ledgerWriter, unitOfWork, outbox, inconsistencies),
+		gatewayMonitor:       gatewayMonitor,
+		stpWebhookSecret:     stpWebhookSecret,
 	}
 }
 
 func (api *API) Router() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", api.health)
-	mux.HandleFunc("/accounts", api.handleAccounts)       // POST
-	mux.HandleFunc("/accounts/", api.handleAccountDetail) // GET /:id, POST /:id/deposit
-	mux.HandleFunc("/transfers", api.transfer)            // POST
+	mux.HandleFunc("/accounts", api.handleAccounts)       // POST, GET ?holder_name=&status=&sort=&cursor=
+	mux.HandleFunc("/accounts/", api.handleAccountDetail) // GET /:id, POST /:id/{deposit,withdraw,freeze,unfreeze,close}, GET /:id/transactions
+	mux.HandleFunc("/transfers", api.handleTransfers)     // POST (book transfer)
+	mux.HandleFunc("/transfers/spei", api.handleOutbound) // POST (to another bank)
+	mux.HandleFunc("/transfers/", api.getTransfer)        // GET /:id
+	mux.HandleFunc("/fx/quotes", api.quote)               // GET ?from=&to=
+	if len(api.stpWebhookSecret) > 0 {
+		mux.HandleFunc("/webhooks/stp/incoming", api.stpIncoming) // POST, signed by STP
+		mux.HandleFunc("/webhooks/stp/status", api.stpStatus)     // POST, signed by STP
+	}
 	return mux
 }
 
+type gatewayHealthResponse struct {
+	State               ports.CircuitState `json:"state"`
+	ConsecutiveFailures int                `json:"consecutive_failures"`
+	RetryAfterSeconds   int                `json:"retry_after_seconds,omitempty"`
+}
+
+// health answers 200 while the process is up; "degraded" means SPEI transfers
+// are refused until the payment gateway's circuit closes again.
 func (api *API) health(w http.ResponseWriter, r *http.Request) {
-	httpx.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	gateway := api.gatewayMonitor.Health()
+	status := "ok"
+	if gateway.State != ports.CircuitClosed {
+		status = "degraded"
+	}
+	response := struct {
+		Status         string                `json:"status"`
+		PaymentGateway gatewayHealthResponse `json:"payment_gateway"`
+	}{status, gatewayHealthResponse{State: gateway.State, ConsecutiveFailures: gateway.ConsecutiveFailures}}
+	if gateway.State == ports.CircuitOpen {
+		response.PaymentGateway.RetryAfterSeconds = retryAfterSeconds(gateway.RetryAfter)
+	}
+	httpx.WriteJSON(w, http.StatusOK, response)
 }
 
-// /accounts -> POST
+// /accounts -> POST (open) or GET (search)
 func (api *API) handleAccounts(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
 		api.createAccount(w, r)
 		return
+	case http.MethodGet:
+		api.listAccounts(w, r)
+		return
 	}
 	httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 }
 
-// /accounts/{id} (GET) or /accounts/{id}/deposit (POST)
+// /accounts/{id} (GET), /accounts/{id}/{deposit,withdraw,freeze,unfreeze,close} (POST)
+// or /accounts/{id}/transactions (GET)
 func (api *API) handleAccountDetail(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/accounts/")
 	parts := strings.Split(path, "/")
@@ -72,7 +147,28 @@ func (api *API) handleAccountDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if len(parts) == 2 && parts[1] == "deposit" && r.Method == http.MethodPost {
-		api.deposit(w, r, accountID)
+		api.withIdempotency(w, r, func(w http.ResponseWriter, r *http.Request) { api.deposit(w, r, accountID) })
+		return
+	}
+	if len(parts) == 2 && parts[1] == "withdraw" && r.Method == http.MethodPost {
+		api.withIdempotency(w, r, func(w http.ResponseWriter, r *http.Request) { api.withdraw(w, r, accountID) })
+		return
+	}
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		switch parts[1] {
+		case "freeze":
+			api.changeStatus(w, r, accountID, api.freezeAccount.Execute)
+			return
+		case "unfreeze":
+			api.changeStatus(w, r, accountID, api.unfreezeAccount.Execute)
+			return
+		case "close":
+			api.changeStatus(w, r, accountID, api.closeAccount.Execute)
+			return
+		}
+	}
+	if len(parts) == 2 && parts[1] == "transactions" && r.Method == http.MethodGet {
+		api.transactions(w, r, accountID)
 		return
 	}
 	httpx.WriteError(w, http.StatusNotFound, "route not found")
@@ -81,6 +177,7 @@ func (api *API) handleAccountDetail(w http.ResponseWriter, r *http.Request) {
 type createAccountRequest struct {
 	HolderName string `json:"holder_name"`
 	CLABE      string `json:"clabe"`
+	Currency   string `json:"currency"`
 }
 
 func (api *API) createAccount(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +189,7 @@ func (api *API) createAccount(w http.ResponseWriter, r *http.Request) {
 	output, err := api.openAccountUseCase.Execute(r.Context(), usecase.OpenAccountInput{
 		HolderName: requestBody.HolderName,
 		CLABE:      requestBody.CLABE,
+		Currency:   requestBody.Currency,
 	})
 	if err != nil {
 		api.mapDomainErr(w, err)
@@ -100,19 +198,61 @@ func (api *API) createAccount(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusCreated, output)
 }
 
+// GET /accounts?holder_name=ali&clabe_prefix=032&status=ACTIVE&currency=MXN
+// &min_balance_cents=0&max_balance_cents=100000&sort=-balance&cursor=…&limit=20
+func (api *API) listAccounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	minBalance, err := queryInt64Ptr(r, "min_balance_cents")
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid min_balance_cents")
+		return
+	}
+	maxBalance, err := queryInt64Ptr(r, "max_balance_cents")
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid max_balance_cents")
+		return
+	}
+	output, err := api.searchAccounts.Execute(r.Context(), usecase.SearchAccountsInput{
+		HolderName:  query.Get("holder_name"),
+		CLABEPrefix: query.Get("clabe_prefix"),
+		Status:      query.Get("status"),
+		Currency:    query.Get("currency"),
+		MinBalance:  minBalance,
+		MaxBalance:  maxBalance,
+		Sort:        query.Get("sort"),
+		Cursor:      query.Get("cursor"),
+		Limit:       limit,
+	})
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
 func (api *API) getAccount(w http.ResponseWriter, r *http.Request, accountID string) {
 	account, err := api.depositMoneyUseCase.ReaderPort().ByID(r.Context(), accountID)
 	if err != nil {
 		httpx.WriteError(w, http.StatusNotFound, "account not found")
 		return
 	}
+	clabe := account.BankAccount()
 	httpx.WriteJSON(w, http.StatusOK, map[string]any{
-		"id": account.ID, "holder_name": account.HolderName(), "clabe": account.CLABE(), "balance_cents": account.Balance(),
+		"id": account.ID, "holder_name": account.HolderName(), "clabe": account.CLABE(),
+		"bank_code": clabe.BankCode(), "bank_name": clabe.BankName(), "plaza": clabe.Plaza(),
+		"status": account.Status(), "currency": account.Currency(), "balance": account.Balance().String(), "balance_cents": account.Balance().Amount(),
+		"available_balance": account.AvailableBalance().String(), "available_balance_cents": account.AvailableBalance().Amount(),
 	})
 }
 
 type depositRequest struct {
-	Cents int64 `json:"cents"`
+	Amount *moneyField `json:"amount"` // "150.00 MXN"
+	Cents  int64       `json:"cents"`  // legacy, MXN only
 }
 
 func (api *API) deposit(w http.ResponseWriter, r *http.Request, accountID string) {
@@ -123,7 +263,67 @@ func (api *API) deposit(w http.ResponseWriter, r *http.Request, accountID string
 	}
 	output, err := api.depositMoneyUseCase.Execute(r.Context(), usecase.DepositInput{
 		AccountID: accountID,
-		Cents:     requestBody.Cents,
+		Amount:    requestAmount(requestBody.Amount, requestBody.Cents),
+	})
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
+type withdrawRequest struct {
+	Amount  *moneyField `json:"amount"`  // "150.00 MXN"
+	Cents   int64       `json:"cents"`   // legacy, MXN only
+	Channel string      `json:"channel"` // ATM, BRANCH or CARD
+}
+
+func (api *API) withdraw(w http.ResponseWriter, r *http.Request, accountID string) {
+	var requestBody withdrawRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	output, err := api.withdrawMoneyUseCase.Execute(r.Context(), usecase.WithdrawInput{
+		AccountID: accountID,
+		Amount:    requestAmount(requestBody.Amount, requestBody.Cents),
+		Channel:   requestBody.Channel,
+	})
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
+// POST /accounts/{id}/freeze|unfreeze|close
+func (api *API) changeStatus(w http.ResponseWriter, r *http.Request, accountID string,
+	execute func(ctx context.Context, accountID string) (usecase.AccountStatusOutput, error),
+) {
+	output, err := execute(r.Context(), accountID)
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
+// GET /accounts/{id}/transactions?offset=0&limit=20
+func (api *API) transactions(w http.ResponseWriter, r *http.Request, accountID string) {
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid offset")
+		return
+	}
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	output, err := api.listTransactions.Execute(r.Context(), usecase.ListTransactionsInput{
+		AccountID: accountID,
+		Offset:    offset,
+		Limit:     limit,
 	})
 	if err != nil {
 		api.mapDomainErr(w, err)
@@ -132,26 +332,106 @@ func (api *API) deposit(w http.ResponseWriter, r *http.Request, accountID string
 	httpx.WriteJSON(w, http.StatusOK, output)
 }
 
+// queryInt reads an optional non-negative integer query parameter (0 when absent).
+func queryInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return value, nil
+}
+
+// queryInt64Ptr reads an optional integer query parameter (nil when absent).
+func queryInt64Ptr(r *http.Request, name string) (*int64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid " + name)
+	}
+	return &value, nil
+}
+
 type transferRequest struct {
-	FromID string `json:"from_id"`
-	ToID   string `json:"to_id"`
-	Cents  int64  `json:"cents"`
+	FromID    string      `json:"from_id"`
+	FromCLABE string      `json:"from_clabe"` // alternative to from_id
+	ToID      string      `json:"to_id"`
+	ToCLABE   string      `json:"to_clabe"` // alternative to to_id
+	Amount    *moneyField `json:"amount"`   // "50.00 MXN", in the source account's currency
+	Cents     int64       `json:"cents"`    // legacy, MXN only
+	QuoteID   string      `json:"quote_id"` // optional, from GET /fx/quotes
 }
 
-func (api *API) transfer(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+// /transfers -> POST
+func (api *API) handleTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		api.withIdempotency(w, r, api.transfer)
 		return
 	}
+	httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (api *API) transfer(w http.ResponseWriter, r *http.Request) {
 	var requestBody transferRequest
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
 		httpx.WriteError(w, http.StatusBadRequest, "invalid json: "+err.Error())
 		return
 	}
 	output, err := api.transferMoneyUseCase.Execute(r.Context(), usecase.TransferInput{
-		FromID: strings.TrimSpace(requestBody.FromID),
-		ToID:   strings.TrimSpace(requestBody.ToID),
-		Cents:  requestBody.Cents,
+		FromID:    strings.TrimSpace(requestBody.FromID),
+		FromCLABE: strings.TrimSpace(requestBody.FromCLABE),
+		ToID:      strings.TrimSpace(requestBody.ToID),
+		ToCLABE:   strings.TrimSpace(requestBody.ToCLABE),
+		Amount:    requestAmount(requestBody.Amount, requestBody.Cents),
+		QuoteID:   strings.TrimSpace(requestBody.QuoteID),
+	})
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, output)
+}
+
+type outboundTransferRequest struct {
+	FromID           string      `json:"from_id"`
+	FromCLABE        string      `json:"from_clabe"` // alternative to from_id
+	BeneficiaryCLABE string      `json:"beneficiary_clabe"`
+	BeneficiaryName  string      `json:"beneficiary_name"`
+	Concept          string      `json:"concept"`
+	Reference        int         `json:"reference"`
+	Amount           *moneyField `json:"amount"` // "50.00 MXN"
+	Cents            int64       `json:"cents"`  // legacy, MXN only
+}
+
+// /transfers/spei -> POST
+func (api *API) handleOutbound(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		api.withIdempotency(w, r, api.outbound)
+		return
+	}
+	httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (api *API) outbound(w http.ResponseWriter, r *http.Request) {
+	var requestBody outboundTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	output, err := api.outboundTransfer.Execute(r.Context(), usecase.OutboundTransferInput{
+		FromID:           strings.TrimSpace(requestBody.FromID),
+		FromCLABE:        strings.TrimSpace(requestBody.FromCLABE),
+		BeneficiaryCLABE: strings.TrimSpace(requestBody.BeneficiaryCLABE),
+		BeneficiaryName:  requestBody.BeneficiaryName,
+		Concept:          requestBody.Concept,
+		Reference:        requestBody.Reference,
+		Amount:           requestAmount(requestBody.Amount, requestBody.Cents),
 	})
 	if err != nil {
 		api.mapDomainErr(w, err)
@@ -160,17 +440,86 @@ func (api *API) transfer(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusAccepted, output)
 }
 
+// GET /fx/quotes?from=USD&to=MXN
+func (api *API) quote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	output, err := api.quoteExchangeRate.Execute(r.Context(), usecase.QuoteExchangeRateInput{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	})
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
+// GET /transfers/{id}
+func (api *API) getTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	transferID := strings.TrimPrefix(r.URL.Path, "/transfers/")
+	if transferID == "" || strings.Contains(transferID, "/") {
+		httpx.WriteError(w, http.StatusNotFound, "route not found")
+		return
+	}
+	output, err := api.getTransferUseCase.Execute(r.Context(), transferID)
+	if err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, output)
+}
+
 // Map domain errors to HTTP responses (adapter concern).
 func (api *API) mapDomainErr(w http.ResponseWriter, err error) {
 	switch {
-	case errors.Is(err, domain.ErrInvalidAmount):
+	case errors.Is(err, domain.ErrAccountNotFound), errors.Is(err, domain.ErrTransferNotFound),
+		errors.Is(err, domain.ErrQuoteNotFound):
+		httpx.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrVersionConflict), errors.Is(err, domain.ErrInvalidAccountTransition),
+		errors.Is(err, domain.ErrDuplicateCLABE), errors.Is(err, domain.ErrInvalidTransition):
+		httpx.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrInvalidMoney),
+		errors.Is(err, domain.ErrUnsupportedCurrency), errors.Is(err, domain.ErrAmountOverflow),
+		errors.Is(err, domain.ErrUnknownWithdrawalChannel),
+		errors.Is(err, ports.ErrInvalidFilter), errors.Is(err, ports.ErrInvalidCursor):
 		httpx.WriteError(w, http.StatusBadRequest, err.Error())
-	case errors.Is(err, domain.ErrInsufficientFund):
+	case errors.Is(err, domain.ErrCurrencyMismatch), errors.Is(err, domain.ErrInvalidQuote),
+		errors.Is(err, domain.ErrQuoteExpired), errors.Is(err, domain.ErrQuoteMismatch):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrInsufficientFund), errors.Is(err, domain.ErrWithdrawalLimitExceeded):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrAccountFrozen), errors.Is(err, domain.ErrAccountClosed), errors.Is(err, domain.ErrAccountNotEmpty):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrInvalidCLABE), errors.Is(err, domain.ErrInvalidCLABEControlDigit), errors.Is(err, domain.ErrUnknownBank):
 		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
-	case errors.Is(err, domain.ErrInvalidCLABE), errors.Is(err, domain.ErrEmptyHolder):
+	case errors.Is(err, domain.ErrEmptyHolder), errors.Is(err, domain.ErrSameAccount):
 		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrInvalidBeneficiary), errors.Is(err, domain.ErrInvalidPaymentDetails), errors.Is(err, domain.ErrLocalBeneficiary):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ports.ErrPaymentUnavailable):
+		var unavailable *ports.GatewayUnavailableError
+		if errors.As(err, &unavailable) {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(unavailable.RetryAfter)))
+		}
+		httpx.WriteError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, ports.ErrPaymentRejected):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ports.ErrPaymentRetryable):
+		httpx.WriteError(w, http.StatusServiceUnavailable, err.Error())
 	default:
 		api.logger.Error("unexpected error", "err", err)
 		httpx.WriteError(w, http.StatusInternalServerError, "internal error")
 	}
 }
+
+// retryAfterSeconds rounds up to whole seconds, at least 1, for Retry-After.
+func retryAfterSeconds(delay time.Duration) int {
+	return max(1, int(math.Ceil(delay.Seconds())))
+}
@@ -0,0 +1,57 @@
+package inhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutboundTransferWhileCircuitOpen(t *testing.T) {
+	api, _ := newTestAPIWith(fixedHealth{State: ports.CircuitOpen, ConsecutiveFailures: 5, RetryAfter: 12300 * time.Millisecond})
+	router := api.Router()
+
+	request := httptest.NewRequest(http.MethodPost, "/transfers/spei", strings.NewReader(
+		`{"from_id":"any","beneficiary_clabe":"012180000118359713","beneficiary_name":"Carol","concept":"rent","reference":1,"amount":"10.00 MXN"}`))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("Retry-After") != "13" {
+		t.Fatalf("want 503 with Retry-After 13 got %d %q: %s", recorder.Code, recorder.Header().Get("Retry-After"), recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var health struct {
+		Status         string                `json:"status"`
+		PaymentGateway gatewayHealthResponse `json:"payment_gateway"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &health); err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if recorder.Code != http.StatusOK || health.Status != "degraded" || health.PaymentGateway.State != ports.CircuitOpen || health.PaymentGateway.RetryAfterSeconds != 13 {
+		t.Fatalf("want degraded with the circuit OPEN got %d %+v", recorder.Code, health)
+	}
+}
+
+func TestPaymentRejectionsMapToStatus(t *testing.T) {
+	api, _ := newTestAPI()
+	for _, test := range []struct {
+		code ports.RejectionCode
+		want int
+	}{
+		{ports.RejectInvalidAccount, http.StatusUnprocessableEntity},
+		{ports.RejectBeneficiaryNameMismatch, http.StatusUnprocessableEntity},
+		{ports.RejectRailUnavailable, http.StatusServiceUnavailable},
+		{ports.RejectTimeout, http.StatusServiceUnavailable},
+	} {
+		recorder := httptest.NewRecorder()
+		api.mapDomainErr(recorder, fmt.Errorf("send transfer: %w", &ports.PaymentError{Code: test.code, Reason: "STP"}))
+		if recorder.Code != test.want || !strings.Contains(recorder.Body.String(), string(test.code)) {
+			t.Fatalf("%s: want %d got %d: %s", test.code, test.want, recorder.Code, recorder.Body)
+		}
+	}
+}
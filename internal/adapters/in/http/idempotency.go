@@ -0,0 +1,121 @@
+package inhttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/shared/httpx"
+	"io"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// withIdempotency honors the Idempotency-Key header for money-moving endpoints:
+// the first response is stored and replayed for repeats of the same request,
+// while reusing a key with a different request is rejected.
+// Requests without the header run normally.
+func (api *API) withIdempotency(w http.ResponseWriter, r *http.Request, handle http.HandlerFunc) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		handle(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		httpx.WriteError(w, http.StatusBadRequest, "idempotency key too long")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httpx.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "cannot read body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	fingerprint := requestFingerprint(r, body)
+
+	stored, err := api.idempotencyStore.Reserve(r.Context(), key, fingerprint)
+	if errors.Is(err, ports.ErrIdempotencyKeyMismatch) {
+		httpx.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if errors.Is(err, ports.ErrIdempotencyKeyInUse) {
+		httpx.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		api.logger.Error("idempotency reserve failed", "key", key, "err", err)
+		httpx.WriteError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if stored != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(stored.StatusCode)
+		_, _ = w.Write(stored.Body)
+		return
+	}
+
+	// Unless the response gets stored, free the key so the client may retry:
+	// server errors are not final, and neither is a handler that panicked.
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := api.idempotencyStore.Release(r.Context(), key); err != nil {
+			api.logger.Warn("idempotency release failed", "key", key, "err", err)
+		}
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	handle(recorder, r)
+	if recorder.statusCode >= http.StatusInternalServerError {
+		return
+	}
+	completed = true
+	err = api.idempotencyStore.Complete(r.Context(), key, ports.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		StatusCode:  recorder.statusCode,
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		api.logger.Warn("idempotency complete failed", "key", key, "err", err)
+	}
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(payload []byte) (int, error) {
+	recorder.body.Write(payload)
+	return recorder.ResponseWriter.Write(payload)
+}
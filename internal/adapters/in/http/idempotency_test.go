@@ -0,0 +1,101 @@
+package inhttp
+
+import (
+	"context"
+	"encoding/json"
+	"hexagonal-bank/internal/shared/httpx"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postWithKey sends a POST carrying an Idempotency-Key through handler.
+func postWithKey(handler http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set(idempotencyKeyHeader, key)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotentDepositIsReplayed(t *testing.T) {
+	api, accounts := newTestAPI()
+	router := api.Router()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"holder_name":"Alice","clabe":"032180000118359719"}`)))
+	var opened struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &opened); err != nil || opened.ID == "" {
+		t.Fatalf("open: %d %s", recorder.Code, recorder.Body)
+	}
+	path := "/accounts/" + opened.ID + "/deposit"
+
+	first := postWithKey(router, path, "key-1", `{"amount":"10.00 MXN"}`)
+	if first.Code != http.StatusOK || first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("first deposit: %d %q %s", first.Code, first.Header().Get(idempotentReplayedHeader), first.Body)
+	}
+	replay := postWithKey(router, path, "key-1", `{"amount":"10.00 MXN"}`)
+	if replay.Code != http.StatusOK || replay.Header().Get(idempotentReplayedHeader) != "true" || replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay: %d %q %s", replay.Code, replay.Header().Get(idempotentReplayedHeader), replay.Body)
+	}
+	if account, _ := accounts.ByID(t.Context(), opened.ID); account.Balance().Amount() != 1000 {
+		t.Fatalf("deposited once: want 1000 got %s", account.Balance())
+	}
+
+	if recorder := postWithKey(router, path, "key-1", `{"amount":"20.00 MXN"}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("same key, different body: want 422 got %d", recorder.Code)
+	}
+	tooLarge := `{"amount":"10.00 MXN","padding":"` + strings.Repeat("x", maxIdempotentRequestBytes) + `"}`
+	if recorder := postWithKey(router, path, "key-2", tooLarge); recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body: want 413 got %d", recorder.Code)
+	}
+}
+
+func TestIdempotencyKeyInProgressConflicts(t *testing.T) {
+	api, _ := newTestAPI()
+	if _, err := api.idempotencyStore.Reserve(context.Background(), "key-1", "fingerprint"); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	body := `{}`
+	if _, err := api.idempotencyStore.Reserve(context.Background(), "key-2", requestFingerprint(httptest.NewRequest(http.MethodPost, "/transfers", nil), []byte(body))); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if recorder := postWithKey(api.Router(), "/transfers", "key-2", body); recorder.Code != http.StatusConflict {
+		t.Fatalf("key in progress: want 409 got %d", recorder.Code)
+	}
+	if recorder := postWithKey(api.Router(), "/transfers", "key-1", body); recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("key in progress for a different request: want 422 got %d", recorder.Code)
+	}
+}
+
+func TestIdempotencyKeyReleasedAfterServerError(t *testing.T) {
+	api, _ := newTestAPI()
+	calls := 0
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.withIdempotency(w, r, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			httpx.WriteError(w, http.StatusInternalServerError, "internal error")
+		})
+	})
+	for range 2 {
+		if recorder := postWithKey(failing, "/transfers", "key-1", `{}`); recorder.Code != http.StatusInternalServerError {
+			t.Fatalf("want 500 got %d", recorder.Code)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("a 5xx is not replayed: want 2 calls got %d", calls)
+	}
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.withIdempotency(w, r, func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	})
+	func() {
+		defer func() { _ = recover() }()
+		postWithKey(panicking, "/transfers", "key-2", `{}`)
+	}()
+	if _, err := api.idempotencyStore.Reserve(context.Background(), "key-2", "fingerprint"); err != nil {
+		t.Fatalf("a panicking handler must release the key: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package inhttp
+
+import (
+	"encoding/json"
+	"hexagonal-bank/internal/core/domain"
+)
+
+// moneyField decodes a JSON amount written as a decimal string with its
+// currency, e.g. "125.50 MXN".
+type moneyField struct {
+	domain.Money
+}
+
+func (field *moneyField) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return domain.ErrInvalidMoney
+	}
+	money, err := domain.ParseMoney(raw)
+	if err != nil {
+		return err
+	}
+	field.Money = money
+	return nil
+}
+
+// requestAmount prefers the "amount" field and falls back to the legacy
+// integer "cents" field, which is always MXN.
+func requestAmount(amount *moneyField, legacyCents int64) domain.Money {
+	if amount != nil {
+		return amount.Money
+	}
+	money, _ := domain.NewMoney(legacyCents, domain.MXN)
+	return money
+}
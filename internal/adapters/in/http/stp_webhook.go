@@ -0,0 +1,222 @@
+package inhttp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/usecase"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/shared/httpx"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stpSignatureHeader = "X-STP-Signature" // hex HMAC-SHA256 of the raw body
+	maxWebhookBytes    = 64 << 10
+)
+
+// STP acknowledgement: "confirmar" accepts the payment; "devolver" returns it
+// to the sender with one of STP's return causes ("causas de devolución").
+// Status changes are acknowledged with "recibido".
+const (
+	stpConfirm  = "confirmar"
+	stpReturn   = "devolver"
+	stpReceived = "recibido"
+
+	returnAccountNotFound  = 1  // cuenta inexistente
+	returnAccountBlocked   = 2  // cuenta bloqueada
+	returnAccountCancelled = 3  // cuenta cancelada
+	returnOtherCurrency    = 5  // cuenta en otra divisa
+	returnMissingData      = 14 // falta información mandatoria
+)
+
+// stpIncomingPayment is STP's "abono" notification for a payment sent to
+// one of our CLABEs.
+type stpIncomingPayment struct {
+	ID                int64       `json:"id"`
+	OperationDate     int         `json:"fechaOperacion"`       // YYYYMMDD
+	OriginInstitution int         `json:"institucionOrdenante"` // Banxico code, e.g. 40012
+	TrackingKey       string      `json:"claveRastreo"`
+	Amount            json.Number `json:"monto"` // pesos, e.g. 125.5
+	OriginName        string      `json:"nombreOrdenante"`
+	OriginCLABE       string      `json:"cuentaOrdenante"`
+	BeneficiaryName   string      `json:"nombreBeneficiario"`
+	BeneficiaryCLABE  string      `json:"cuentaBeneficiario"`
+	Concept           string      `json:"conceptoPago"`
+	Reference         int         `json:"referenciaNumerica"`
+}
+
+type stpAcknowledgement struct {
+	Message     string `json:"mensaje"`
+	ReturnCause int    `json:"id,omitempty"`
+}
+
+// POST /webhooks/stp/incoming
+// Every well-formed, signed notification gets a 200 with an acknowledgement;
+// 401/400/500 make STP deliver it again later.
+func (api *API) stpIncoming(w http.ResponseWriter, r *http.Request) {
+	body, ok := api.readSignedBody(w, r)
+	if !ok {
+		return
+	}
+	var payment stpIncomingPayment
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payment); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	amount, err := domain.ParseMoney(payment.Amount.String() + " MXN")
+	if err == nil {
+		_, err = api.receiveIncoming.Execute(r.Context(), usecase.IncomingTransferInput{
+			TrackingKey:      payment.TrackingKey,
+			OperationDate:    operationDate(payment.OperationDate),
+			BeneficiaryCLABE: strings.TrimSpace(payment.BeneficiaryCLABE),
+			OriginCLABE:      strings.TrimSpace(payment.OriginCLABE),
+			OriginName:       strings.TrimSpace(payment.OriginName),
+			OriginBankCode:   bankCode(payment.OriginInstitution),
+			Concept:          payment.Concept,
+			Reference:        payment.Reference,
+			Amount:           amount,
+		})
+	}
+	if err == nil {
+		httpx.WriteJSON(w, http.StatusOK, stpAcknowledgement{Message: stpConfirm})
+		return
+	}
+	cause, returnable := returnCause(err)
+	if !returnable {
+		api.logger.Error("incoming transfer failed", "tracking_key", payment.TrackingKey, "err", err)
+		httpx.WriteError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	api.logger.Warn("incoming transfer returned", "tracking_key", payment.TrackingKey, "cause", cause, "err", err)
+	httpx.WriteJSON(w, http.StatusOK, stpAcknowledgement{Message: stpReturn, ReturnCause: cause})
+}
+
+// STP status values of a "cambio de estado" notification.
+const (
+	stpStatusSettled  = "LIQUIDADA"
+	stpStatusReturned = "DEVUELTA"
+)
+
+// stpStatusChange is STP's "cambio de estado" notification: the final outcome
+// of an order we sent.
+type stpStatusChange struct {
+	ID          int64  `json:"id"`
+	TrackingKey string `json:"claveRastreo"`
+	Status      string `json:"estado"`          // LIQUIDADA or DEVUELTA
+	ReturnCause string `json:"causaDevolucion"` // for DEVUELTA
+}
+
+// POST /webhooks/stp/status
+// Anything but a 200 makes STP deliver the notification again later.
+func (api *API) stpStatus(w http.ResponseWriter, r *http.Request) {
+	body, ok := api.readSignedBody(w, r)
+	if !ok {
+		return
+	}
+	var change stpStatusChange
+	if err := json.Unmarshal(body, &change); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	input := usecase.TransferStatusInput{TrackingKey: change.TrackingKey, Reason: change.ReturnCause}
+	switch change.Status {
+	case stpStatusSettled:
+		input.Status = domain.TransferSettled
+	case stpStatusReturned:
+		input.Status = domain.TransferReturned
+	default:
+		httpx.WriteError(w, http.StatusBadRequest, "unknown estado: "+change.Status)
+		return
+	}
+	if _, err := api.updateTransferStatus.Execute(r.Context(), input); err != nil {
+		api.mapDomainErr(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, stpAcknowledgement{Message: stpReceived})
+}
+
+// readSignedBody reads a webhook body and checks its signature, answering
+// the request itself when either fails.
+func (api *API) readSignedBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		httpx.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBytes))
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "cannot read body")
+		return nil, false
+	}
+	if !validSignature(api.stpWebhookSecret, body, r.Header.Get(stpSignatureHeader)) {
+		httpx.WriteError(w, http.StatusUnauthorized, "invalid signature")
+		return nil, false
+	}
+	return body, true
+}
+
+// validSignature compares in constant time so the signature cannot be
+// guessed byte by byte.
+func validSignature(secret, body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// bankCode turns a Banxico institution code (40012) into the 3-digit bank
+// code used in CLABEs ("012").
+func bankCode(institution int) string {
+	if institution <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%03d", institution%1000)
+}
+
+// operationDate reads STP's YYYYMMDD date; anything else is the zero time,
+// which the domain rejects as missing.
+func operationDate(yyyymmdd int) time.Time {
+	date, err := time.Parse("20060102", strconv.Itoa(yyyymmdd))
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// returnCause maps why a payment cannot be credited to an STP return cause.
+// Anything else is our problem, not the sender's, and is not returnable.
+func returnCause(err error) (int, bool) {
+	switch {
+	case errors.Is(err, domain.ErrAccountNotFound),
+		errors.Is(err, domain.ErrInvalidCLABE),
+		errors.Is(err, domain.ErrInvalidCLABEControlDigit),
+		errors.Is(err, domain.ErrUnknownBank):
+		return returnAccountNotFound, true
+	case errors.Is(err, domain.ErrAccountFrozen):
+		return returnAccountBlocked, true
+	case errors.Is(err, domain.ErrAccountClosed):
+		return returnAccountCancelled, true
+	case errors.Is(err, domain.ErrCurrencyMismatch):
+		return returnOtherCurrency, true
+	case errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrInvalidMoney),
+		errors.Is(err, domain.ErrAmountOverflow),
+		errors.Is(err, domain.ErrInvalidPaymentDetails):
+		return returnMissingData, true
+	}
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package inhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hexagonal-bank/internal/adapters/out/fx"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testWebhookSecret = []byte("test-secret")
+
+// fixedHealth is a GatewayMonitor that always reports the same health.
+type fixedHealth ports.GatewayHealth
+
+func (health fixedHealth) Health() ports.GatewayHealth { return ports.GatewayHealth(health) }
+
+func newTestAPI() (*API, *memory.AccountRepository) {
+	return newTestAPIWith(fixedHealth{State: ports.CircuitClosed})
+}
+
+func newTestAPIWith(gatewayMonitor ports.GatewayMonitor) (*API, *memory.AccountRepository) {
+	accounts := memory.NewAccountRepo()
+	transfers := memory.NewTransferRepo()
+	ledger := memory.NewLedgerRepo()
+	api := NewAPI(
+		logging.NewStd(), accounts, accounts, accounts, transfers, transfers,
+		ledger, ledger, ledger, accounts, memory.NewOutbox(), memory.NewInconsistencyLog(), memory.NewIdempotencyStore(time.Hour),
+		fx.NewFakeRateProvider(time.Minute), gatewayMonitor, testWebhookSecret,
+	)
+	return api, accounts
+}
+
+func postSigned(t *testing.T, handler http.Handler, body, signature string) (*httptest.ResponseRecorder, stpAcknowledgement) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/webhooks/stp/incoming", strings.NewReader(body))
+	request.Header.Set(stpSignatureHeader, signature)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	var ack stpAcknowledgement
+	_ = json.Unmarshal(recorder.Body.Bytes(), &ack)
+	return recorder, ack
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, testWebhookSecret)
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSTPIncomingWebhook(t *testing.T) {
+	api, accounts := newTestAPI()
+	router := api.Router()
+	request := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"holder_name":"Bob","clabe":"002180000118359710"}`))
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	payment := `{"fechaOperacion":20251016,"claveRastreo":"BBVA42","monto":125.5,"cuentaBeneficiario":"002180000118359710",` +
+		`"cuentaOrdenante":"012180000118359713","nombreOrdenante":"Carol","institucionOrdenante":40012,` +
+		`"conceptoPago":"invoice 7","referenciaNumerica":7}`
+
+	if recorder, _ := postSigned(t, router, payment, sign(payment+" ")); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: want 401 got %d", recorder.Code)
+	}
+	for range 2 { // STP redelivers until it gets an acknowledgement
+		if recorder, ack := postSigned(t, router, payment, sign(payment)); recorder.Code != http.StatusOK || ack.Message != stpConfirm {
+			t.Fatalf("want 200 confirmar got %d %+v", recorder.Code, ack)
+		}
+	}
+	bob, err := accounts.ByCLABE(t.Context(), "002180000118359710")
+	if err != nil {
+		t.Fatalf("bob: %v", err)
+	}
+	if bob.Balance().Amount() != 12550 {
+		t.Fatalf("credited once: want 12550 got %d", bob.Balance().Amount())
+	}
+
+	nextDay := strings.Replace(payment, "20251016", "20251017", 1)
+	if _, ack := postSigned(t, router, nextDay, sign(nextDay)); ack.Message != stpConfirm {
+		t.Fatalf("same key on another day: want confirmar got %+v", ack)
+	}
+	if bob, _ := accounts.ByCLABE(t.Context(), "002180000118359710"); bob.Balance().Amount() != 25100 {
+		t.Fatalf("a new operation date is a new payment: want 25100 got %d", bob.Balance().Amount())
+	}
+	undated := strings.Replace(payment, `"fechaOperacion":20251016,`, "", 1)
+	if _, ack := postSigned(t, router, undated, sign(undated)); ack.Message != stpReturn || ack.ReturnCause != returnMissingData {
+		t.Fatalf("missing operation date: want devolver/%d got %+v", returnMissingData, ack)
+	}
+
+	unknown := strings.Replace(payment, "002180000118359710", "646180000118359710", 1)
+	unknown = strings.Replace(unknown, "BBVA42", "BBVA43", 1)
+	if _, ack := postSigned(t, router, unknown, sign(unknown)); ack.Message != stpReturn || ack.ReturnCause != returnAccountNotFound {
+		t.Fatalf("unknown CLABE: want devolver/%d got %+v", returnAccountNotFound, ack)
+	}
+}
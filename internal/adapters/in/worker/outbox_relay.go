@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/backoff"
+	"hexagonal-bank/internal/platform/logging"
+	"time"
+)
+
+// OutboxRelay drains the outbox into the EventPublisher (at-least-once).
+// Failed messages are retried with exponential backoff + full jitter.
+type OutboxRelay struct {
+	logger         logging.Logger
+	store          ports.OutboxRelayStore
+	eventPublisher ports.EventPublisher
+	pollInterval   time.Duration
+	batchSize      int
+}
+
+func NewOutboxRelay(logger logging.Logger, store ports.OutboxRelayStore, eventPublisher ports.EventPublisher, pollInterval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		logger:         logger,
+		store:          store,
+		eventPublisher: eventPublisher,
+		pollInterval:   pollInterval,
+		batchSize:      100,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.pollInterval)
+	defer ticker.Stop()
+	for {
+		relay.DrainOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DrainOnce publishes every due message once and returns how many succeeded.
+func (relay *OutboxRelay) DrainOnce(ctx context.Context) int {
+	const (
+		baseDelay  = 500 * time.Millisecond
+		multiplier = 2.0
+		maxDelay   = time.Minute
+	)
+	messages, err := relay.store.Due(ctx, time.Now(), relay.batchSize)
+	if err != nil {
+		relay.logger.Error("outbox read failed", "err", err)
+		return 0
+	}
+	published := 0
+	for _, message := range messages {
+		if err := relay.eventPublisher.Publish(ctx, message.Topic, message.Payload); err != nil {
+			sleepDuration := backoff.FullJitter(message.Attempts, baseDelay, multiplier, maxDelay)
+			relay.logger.Warn("outbox publish failed, retrying", "id", message.ID, "attempt", message.Attempts, "sleep", sleepDuration, "err", err)
+			if err := relay.store.ScheduleRetry(ctx, message.ID, time.Now().Add(sleepDuration), err.Error()); err != nil {
+				relay.logger.Error("outbox reschedule failed", "id", message.ID, "err", err)
+			}
+			continue
+		}
+		if err := relay.store.MarkPublished(ctx, message.ID); err != nil {
+			// The message will be published again: consumers must be idempotent.
+			relay.logger.Error("outbox mark published failed", "id", message.ID, "err", err)
+			continue
+		}
+		published++
+	}
+	return published
+}
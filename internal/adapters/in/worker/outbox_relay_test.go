@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/platform/logging"
+	"testing"
+	"time"
+)
+
+type flakyPublisher struct {
+	failures  int
+	published []string
+}
+
+func (publisher *flakyPublisher) Publish(ctx context.Context, topic string, payload any) error {
+	if publisher.failures > 0 {
+		publisher.failures--
+		return errors.New("broker down")
+	}
+	publisher.published = append(publisher.published, topic)
+	return nil
+}
+
+func TestOutboxRelayRetriesUntilPublished(t *testing.T) {
+	ctx := context.Background()
+	outbox := memory.NewOutbox()
+	publisher := &flakyPublisher{failures: 1}
+	relay := NewOutboxRelay(logging.NewStd(), outbox, publisher, time.Second)
+
+	if err := outbox.Enqueue(ctx, "transfer.completed", map[string]any{"cents": 100}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if published := relay.DrainOnce(ctx); published != 0 {
+		t.Fatalf("first drain: want=0 got=%d", published)
+	}
+
+	pending, _ := outbox.Due(ctx, time.Now().Add(time.Hour), 10)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("want one rescheduled message, got %+v", pending)
+	}
+
+	// Force the retry to be due now instead of sleeping through the backoff.
+	_ = outbox.ScheduleRetry(ctx, pending[0].ID, time.Now(), pending[0].LastError)
+	if published := relay.DrainOnce(ctx); published != 1 {
+		t.Fatalf("second drain: want=1 got=%d", published)
+	}
+	if remaining, _ := outbox.Due(ctx, time.Now().Add(time.Hour), 10); len(remaining) != 0 {
+		t.Fatalf("outbox should be empty, got %d", len(remaining))
+	}
+}
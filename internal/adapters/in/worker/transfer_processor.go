@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/usecase"
+	"hexagonal-bank/internal/platform/logging"
+	"time"
+)
+
+// TransferProcessor periodically runs ProcessTransfersUseCase so the HTTP
+// API can accept transfers without waiting for the payment rail.
+type TransferProcessor struct {
+	logger       logging.Logger
+	useCase      *usecase.ProcessTransfersUseCase
+	pollInterval time.Duration
+	batchSize    int
+}
+
+func NewTransferProcessor(logger logging.Logger, useCase *usecase.ProcessTransfersUseCase, pollInterval time.Duration) *TransferProcessor {
+	return &TransferProcessor{logger: logger, useCase: useCase, pollInterval: pollInterval, batchSize: 50}
+}
+
+// Run processes pending transfers until ctx is cancelled.
+func (processor *TransferProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(processor.pollInterval)
+	defer ticker.Stop()
+	for {
+		output, err := processor.useCase.Execute(ctx, processor.batchSize)
+		if err != nil {
+			processor.logger.Error("transfer processing failed", "err", err)
+		}
+		if output.Deferred > 0 {
+			processor.logger.Warn("payment rail unavailable, transfers left pending", "deferred", output.Deferred)
+		}
+		if output.Reversed > 0 {
+			processor.logger.Warn("transfers reversed, manual review needed", "reversed", output.Reversed)
+		}
+		if output.Sent > 0 || output.Settled > 0 || output.Failed > 0 {
+			processor.logger.Info("transfers processed", "sent", output.Sent, "settled", output.Settled, "failed", output.Failed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
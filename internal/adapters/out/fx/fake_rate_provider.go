@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/shared/id"
+	"sync"
+	"time"
+)
+
+// fixedRates are deterministic mid-market rates (6 decimals, see domain.FXRateScale).
+var fixedRates = map[[2]domain.Currency]int64{
+	{domain.USD, domain.MXN}: 17_000_000,
+	{domain.MXN, domain.USD}: 58_824,
+	{domain.EUR, domain.MXN}: 18_500_000,
+	{domain.MXN, domain.EUR}: 54_054,
+	{domain.EUR, domain.USD}: 1_080_000,
+	{domain.USD, domain.EUR}: 925_926,
+}
+
+// FakeRateProvider quotes fixed rates with a short validity window (thread-safe).
+type FakeRateProvider struct {
+	mutex  sync.Mutex
+	ttl    time.Duration
+	now    func() time.Time
+	quotes map[string]domain.FXQuote
+}
+
+func NewFakeRateProvider(ttl time.Duration) *FakeRateProvider {
+	return &FakeRateProvider{ttl: ttl, now: time.Now, quotes: make(map[string]domain.FXQuote)}
+}
+
+// WithClock replaces time.Now, e.g. to test expiry deterministically.
+func (provider *FakeRateProvider) WithClock(now func() time.Time) *FakeRateProvider {
+	provider.now = now
+	return provider
+}
+
+func (provider *FakeRateProvider) Quote(ctx context.Context, from, to domain.Currency) (domain.FXQuote, error) {
+	rateMicros, exists := fixedRates[[2]domain.Currency{from, to}]
+	if !exists {
+		return domain.FXQuote{}, domain.ErrInvalidQuote
+	}
+	quote, err := domain.NewFXQuote(id.New(), from, to, rateMicros, provider.now().Add(provider.ttl))
+	if err != nil {
+		return domain.FXQuote{}, err
+	}
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.quotes[quote.ID()] = quote
+	return quote, nil
+}
+
+func (provider *FakeRateProvider) QuoteByID(ctx context.Context, quoteID string) (domain.FXQuote, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	quote, exists := provider.quotes[quoteID]
+	if !exists {
+		return domain.FXQuote{}, domain.ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+// Ensure interface compliance
+var _ ports.FXRateProvider = (*FakeRateProvider)(nil)
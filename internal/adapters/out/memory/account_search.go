@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"slices"
+	"strings"
+)
+
+// searchCursor is the position after the last account of a page: its sort key
+// and ID (IDs break ties, so the order is total).
+type searchCursor struct {
+	SortBy  ports.AccountSort `json:"s"`
+	Name    string            `json:"n,omitempty"`
+	Balance int64             `json:"b,omitempty"`
+	ID      string            `json:"i"`
+}
+
+// SearchAccounts filters committed accounts, orders them and returns the page
+// after filter.Cursor.
+func (repository *AccountRepository) SearchAccounts(ctx context.Context, filter ports.AccountFilter) (ports.AccountPage, error) {
+	sortBy := cmp.Or(filter.SortBy, ports.SortByID)
+	var after *searchCursor
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.SortBy != sortBy {
+			return ports.AccountPage{}, ports.ErrInvalidCursor
+		}
+		after = &cursor
+	}
+
+	repository.mutex.RLock()
+	var matches []searchCursor
+	accounts := make(map[string]*domain.Account)
+	for _, account := range repository.data {
+		if matchesFilter(account, filter) {
+			matches = append(matches, cursorOf(account, sortBy))
+			accounts[account.ID] = cloneAccount(account)
+		}
+	}
+	repository.mutex.RUnlock()
+
+	compare := func(a, b searchCursor) int {
+		order := cmp.Compare(a.ID, b.ID)
+		switch sortBy {
+		case ports.SortByHolderName:
+			order = cmp.Or(cmp.Compare(a.Name, b.Name), order)
+		case ports.SortByBalance:
+			order = cmp.Or(cmp.Compare(a.Balance, b.Balance), order)
+		}
+		if filter.Descending {
+			return -order
+		}
+		return order
+	}
+	slices.SortFunc(matches, compare)
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(matches, *after, compare)
+		if start < len(matches) && matches[start].ID == after.ID {
+			start++
+		}
+	}
+	end := min(start+filter.Limit, len(matches))
+	if filter.Limit <= 0 {
+		end = len(matches)
+	}
+
+	page := ports.AccountPage{Accounts: make([]*domain.Account, 0, end-start)}
+	for _, match := range matches[start:end] {
+		page.Accounts = append(page.Accounts, accounts[match.ID])
+	}
+	if end < len(matches) && end > start {
+		page.NextCursor = encodeCursor(matches[end-1])
+	}
+	return page, nil
+}
+
+func matchesFilter(account *domain.Account, filter ports.AccountFilter) bool {
+	if filter.HolderName != "" && !strings.Contains(strings.ToLower(account.HolderName()), strings.ToLower(filter.HolderName)) {
+		return false
+	}
+	if !strings.HasPrefix(account.CLABE(), filter.CLABEPrefix) {
+		return false
+	}
+	if filter.Status != "" && account.Status() != filter.Status {
+		return false
+	}
+	if filter.Currency != "" && account.Currency() != filter.Currency {
+		return false
+	}
+	balance := account.Balance().Amount()
+	if filter.MinBalance != nil && balance < *filter.MinBalance {
+		return false
+	}
+	if filter.MaxBalance != nil && balance > *filter.MaxBalance {
+		return false
+	}
+	return true
+}
+
+func cursorOf(account *domain.Account, sortBy ports.AccountSort) searchCursor {
+	cursor := searchCursor{SortBy: sortBy, ID: account.ID}
+	switch sortBy {
+	case ports.SortByHolderName:
+		cursor.Name = strings.ToLower(account.HolderName())
+	case ports.SortByBalance:
+		cursor.Balance = account.Balance().Amount()
+	}
+	return cursor
+}
+
+func encodeCursor(cursor searchCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (searchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return searchCursor{}, err
+	}
+	var cursor searchCursor
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.AccountQuery = (*AccountRepository)(nil)
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore keeps idempotency records in memory (thread-safe).
+// Records older than ttl are forgotten, and swept out at most once per ttl.
+type IdempotencyStore struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	records   map[string]ports.IdempotencyRecord
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{ttl: ttl, records: make(map[string]ports.IdempotencyRecord), now: time.Now}
+}
+
+func (store *IdempotencyStore) Reserve(ctx context.Context, key, fingerprint string) (*ports.IdempotencyRecord, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	now := store.now()
+	store.sweep(now)
+	if record, exists := store.records[key]; exists && store.live(record, now) {
+		switch {
+		case record.Fingerprint != fingerprint:
+			return nil, ports.ErrIdempotencyKeyMismatch
+		case record.StatusCode == 0:
+			return nil, ports.ErrIdempotencyKeyInUse
+		}
+		return &record, nil
+	}
+	store.records[key] = ports.IdempotencyRecord{Fingerprint: fingerprint, CreatedAt: now}
+	return nil, nil
+}
+
+func (store *IdempotencyStore) live(record ports.IdempotencyRecord, now time.Time) bool {
+	return now.Sub(record.CreatedAt) < store.ttl
+}
+
+// sweep drops expired records so keys that are never reused do not pile up.
+func (store *IdempotencyStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < store.ttl {
+		return
+	}
+	store.lastSweep = now
+	for key, record := range store.records {
+		if !store.live(record, now) {
+			delete(store.records, key)
+		}
+	}
+}
+
+func (store *IdempotencyStore) Complete(ctx context.Context, key string, record ports.IdempotencyRecord) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if reserved, exists := store.records[key]; exists {
+		record.CreatedAt = reserved.CreatedAt
+	}
+	store.records[key] = record
+	return nil
+}
+
+func (store *IdempotencyStore) Release(ctx context.Context, key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.records, key)
+	return nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.IdempotencyStore = (*IdempotencyStore)(nil)
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyStoreRejectsADifferentRequestInProgress(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+	if _, err := store.Reserve(ctx, "key-1", "a"); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if _, err := store.Reserve(ctx, "key-1", "a"); !errors.Is(err, ports.ErrIdempotencyKeyInUse) {
+		t.Fatalf("same request in progress: want ErrIdempotencyKeyInUse got %v", err)
+	}
+	if _, err := store.Reserve(ctx, "key-1", "b"); !errors.Is(err, ports.ErrIdempotencyKeyMismatch) {
+		t.Fatalf("different request in progress: want ErrIdempotencyKeyMismatch got %v", err)
+	}
+
+	store.Complete(ctx, "key-1", ports.IdempotencyRecord{Fingerprint: "a", StatusCode: 200})
+	if record, err := store.Reserve(ctx, "key-1", "a"); err != nil || record == nil || record.StatusCode != 200 {
+		t.Fatalf("want the stored record got %+v %v", record, err)
+	}
+	if _, err := store.Reserve(ctx, "key-1", "b"); !errors.Is(err, ports.ErrIdempotencyKeyMismatch) {
+		t.Fatalf("different request after completion: want ErrIdempotencyKeyMismatch got %v", err)
+	}
+}
+
+func TestIdempotencyStoreEvictsExpiredRecords(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	store.Reserve(ctx, "key-1", "a")
+	store.Complete(ctx, "key-1", ports.IdempotencyRecord{Fingerprint: "a", StatusCode: 200})
+	store.Reserve(ctx, "key-2", "a")
+
+	now = now.Add(time.Hour)
+	if record, err := store.Reserve(ctx, "key-3", "a"); err != nil || record != nil {
+		t.Fatalf("reserve: %+v %v", record, err)
+	}
+	if len(store.records) != 1 {
+		t.Fatalf("expired records must be evicted, %d left", len(store.records))
+	}
+	if record, err := store.Reserve(ctx, "key-1", "b"); err != nil || record != nil {
+		t.Fatalf("an expired key is free again, got %+v %v", record, err)
+	}
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"sync"
+)
+
+// InconsistencyLog keeps inconsistencies for manual review in memory (thread-safe).
+type InconsistencyLog struct {
+	mutex           sync.Mutex
+	inconsistencies []ports.Inconsistency
+}
+
+func NewInconsistencyLog() *InconsistencyLog { return &InconsistencyLog{} }
+
+func (log *InconsistencyLog) RecordInconsistency(ctx context.Context, inconsistency ports.Inconsistency) error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	log.inconsistencies = append(log.inconsistencies, inconsistency)
+	return nil
+}
+
+// All returns a copy of every recorded inconsistency, oldest first.
+func (log *InconsistencyLog) All() []ports.Inconsistency {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return append([]ports.Inconsistency(nil), log.inconsistencies...)
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.InconsistencyRecorder = (*InconsistencyLog)(nil)
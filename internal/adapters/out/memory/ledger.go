@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"sync"
+)
+
+// LedgerRepository is an append-only in-memory journal (thread-safe).
+type LedgerRepository struct {
+	mutex     sync.RWMutex
+	entries   []ledger.Entry
+	balances  map[string]int64
+	byAccount map[string][]int // account ID -> indexes into entries
+}
+
+func NewLedgerRepo() *LedgerRepository {
+	return &LedgerRepository{balances: make(map[string]int64), byAccount: make(map[string][]int)}
+}
+
+// Post appends entry; inside a unit of work the append waits for commit.
+func (repository *LedgerRepository) Post(ctx context.Context, entry ledger.Entry) error {
+	if enlist(ctx, func() { repository.append(entry) }) {
+		return nil
+	}
+	repository.append(entry)
+	return nil
+}
+
+func (repository *LedgerRepository) append(entry ledger.Entry) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.entries = append(repository.entries, entry)
+	index := len(repository.entries) - 1
+	for _, leg := range entry.Legs {
+		repository.balances[leg.AccountID] += leg.Amount
+		indexes := repository.byAccount[leg.AccountID]
+		if len(indexes) == 0 || indexes[len(indexes)-1] != index {
+			repository.byAccount[leg.AccountID] = append(indexes, index)
+		}
+	}
+}
+
+func (repository *LedgerRepository) BalanceOf(ctx context.Context, accountID string) (int64, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+	return repository.balances[accountID], nil
+}
+
+func (repository *LedgerRepository) Transactions(ctx context.Context, accountID string, offset, limit int) ([]ledger.Line, int, error) {
+	repository.mutex.RLock()
+	indexes := repository.byAccount[accountID]
+	entries := make([]ledger.Entry, 0, len(indexes))
+	for _, index := range indexes {
+		entries = append(entries, repository.entries[index])
+	}
+	repository.mutex.RUnlock()
+
+	lines := ledger.Statement(accountID, entries)
+	total := len(lines)
+	// Newest first: walk the chronological statement backwards.
+	page := make([]ledger.Line, 0, limit)
+	for position := total - 1 - offset; position >= 0 && len(page) < limit; position-- {
+		page = append(page, lines[position])
+	}
+	return page, total, nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.LedgerReader = (*LedgerRepository)(nil)
+var _ ports.LedgerWriter = (*LedgerRepository)(nil)
+var _ ports.TransactionReader = (*LedgerRepository)(nil)
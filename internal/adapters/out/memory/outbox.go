@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/shared/id"
+	"sync"
+	"time"
+)
+
+// Outbox is an in-memory transactional outbox (thread-safe).
+// Enqueue joins the caller's unit of work; published messages are dropped.
+type Outbox struct {
+	mutex    sync.Mutex
+	order    []string // message IDs in enqueue order
+	messages map[string]*ports.OutboxMessage
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{messages: make(map[string]*ports.OutboxMessage)}
+}
+
+func (outbox *Outbox) Enqueue(ctx context.Context, topic string, payload any) error {
+	now := time.Now()
+	message := &ports.OutboxMessage{ID: id.New(), Topic: topic, Payload: payload, NextAttemptAt: now, CreatedAt: now}
+	if enlist(ctx, func() { outbox.append(message) }) {
+		return nil
+	}
+	outbox.append(message)
+	return nil
+}
+
+func (outbox *Outbox) append(message *ports.OutboxMessage) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	outbox.order = append(outbox.order, message.ID)
+	outbox.messages[message.ID] = message
+}
+
+func (outbox *Outbox) Due(ctx context.Context, now time.Time, limit int) ([]ports.OutboxMessage, error) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	var due []ports.OutboxMessage
+	for _, messageID := range outbox.order {
+		if len(due) == limit {
+			break
+		}
+		if message := outbox.messages[messageID]; !message.NextAttemptAt.After(now) {
+			due = append(due, *message)
+		}
+	}
+	return due, nil
+}
+
+func (outbox *Outbox) MarkPublished(ctx context.Context, messageID string) error {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	delete(outbox.messages, messageID)
+	for index, queuedID := range outbox.order {
+		if queuedID == messageID {
+			outbox.order = append(outbox.order[:index], outbox.order[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (outbox *Outbox) ScheduleRetry(ctx context.Context, messageID string, nextAttemptAt time.Time, lastError string) error {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	if message, exists := outbox.messages[messageID]; exists {
+		message.Attempts++
+		message.NextAttemptAt = nextAttemptAt
+		message.LastError = lastError
+	}
+	return nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.Outbox = (*Outbox)(nil)
+var _ ports.OutboxRelayStore = (*Outbox)(nil)
@@ -3,48 +3,99 @@ package memory
 import (
 	"context"
 	"errors"
+	"fmt"
 	"hexagonal-bank/internal/core/application/ports"
 	"hexagonal-bank/internal/core/domain"
 	"sync"
 )
 
 // AccountRepository is an in-memory repository simulating a database (thread-safe).
+// It also acts as the unit of work (see unit_of_work.go).
 type AccountRepository struct {
-	mutex sync.RWMutex
-	data  map[string]*domain.Account
+	mutex   sync.RWMutex
+	txMutex sync.Mutex
+	data    map[string]*domain.Account
+	byCLABE map[string]string // CLABE -> account ID (unique index)
 }
 
 func NewAccountRepo() *AccountRepository {
-	return &AccountRepository{data: make(map[string]*domain.Account)}
+	return &AccountRepository{data: make(map[string]*domain.Account), byCLABE: make(map[string]string)}
 }
 
 func (repository *AccountRepository) ByID(ctx context.Context, id string) (*domain.Account, error) {
+	if tx := transactionFrom(ctx); tx != nil {
+		if staged, exists := tx.accounts[id]; exists {
+			return cloneAccount(staged), nil
+		}
+	}
 	repository.mutex.RLock()
 	defer repository.mutex.RUnlock()
 	account, exists := repository.data[id]
 	if !exists {
-		return nil, errors.New("not found")
+		return nil, domain.ErrAccountNotFound
 	}
 	return cloneAccount(account), nil
 }
 
+func (repository *AccountRepository) ByCLABE(ctx context.Context, clabe string) (*domain.Account, error) {
+	if tx := transactionFrom(ctx); tx != nil {
+		for accountID := range tx.created {
+			if staged := tx.accounts[accountID]; staged.CLABE() == clabe {
+				return cloneAccount(staged), nil
+			}
+		}
+	}
+	repository.mutex.RLock()
+	accountID, exists := repository.byCLABE[clabe]
+	repository.mutex.RUnlock()
+	if !exists {
+		return nil, domain.ErrAccountNotFound
+	}
+	return repository.ByID(ctx, accountID)
+}
+
+// Save stores account if its Version matches the stored one (optimistic
+// concurrency) and bumps account.Version; stale writes get a VersionConflictError.
 func (repository *AccountRepository) Save(ctx context.Context, account *domain.Account) error {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx.stageSave(account)
+	}
 	repository.mutex.Lock()
 	defer repository.mutex.Unlock()
+	stored, exists := repository.data[account.ID]
+	if !exists {
+		return domain.ErrAccountNotFound
+	}
+	if stored.Version != account.Version {
+		return &domain.VersionConflictError{AccountID: account.ID, ExpectedVersion: account.Version, ActualVersion: stored.Version}
+	}
+	account.Version++
 	repository.data[account.ID] = cloneAccount(account)
 	return nil
 }
 
 func (repository *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx.stageCreate(account)
+	}
 	repository.mutex.Lock()
 	defer repository.mutex.Unlock()
 	if _, exists := repository.data[account.ID]; exists {
 		return errors.New("already exists")
 	}
+	if _, taken := repository.byCLABE[account.CLABE()]; taken {
+		return duplicateCLABE(account)
+	}
+	account.Version = 1
 	repository.data[account.ID] = cloneAccount(account)
+	repository.byCLABE[account.CLABE()] = account.ID
 	return nil
 }
 
+func duplicateCLABE(account *domain.Account) error {
+	return fmt.Errorf("%w: %s", domain.ErrDuplicateCLABE, account.CLABE())
+}
+
 func cloneAccount(account *domain.Account) *domain.Account {
 	copy := *account
 	return &copy
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+)
+
+func TestSaveRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo()
+
+	clabe, _ := domain.NewCLABE("032180000118359719")
+	account, _ := domain.NewAccount("acc-1", "Alice", clabe, domain.MXN)
+	if err := repository.Create(ctx, account); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	first, _ := repository.ByID(ctx, "acc-1")
+	second, _ := repository.ByID(ctx, "acc-1")
+
+	_ = first.Credit(mxn(100))
+	if err := repository.Save(ctx, first); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	_ = second.Credit(mxn(200))
+	err := repository.Save(ctx, second)
+	var conflict *domain.VersionConflictError
+	if !errors.Is(err, domain.ErrVersionConflict) || !errors.As(err, &conflict) {
+		t.Fatalf("want version conflict got %v", err)
+	}
+	if conflict.ExpectedVersion != 1 || conflict.ActualVersion != 2 {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+
+	stored, _ := repository.ByID(ctx, "acc-1")
+	if stored.Balance().Amount() != 100 {
+		t.Fatalf("want=100 got=%d", stored.Balance().Amount())
+	}
+}
+
+func mxn(cents int64) domain.Money {
+	money, _ := domain.NewMoney(cents, domain.MXN)
+	return money
+}
+
+func TestCreateEnforcesUniqueCLABE(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo()
+	clabe, _ := domain.NewCLABE("032180000118359719")
+	first, _ := domain.NewAccount("acc-1", "Alice", clabe, domain.MXN)
+	if err := repository.Create(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	duplicate, _ := domain.NewAccount("acc-2", "Mallory", clabe, domain.MXN)
+	if err := repository.Create(ctx, duplicate); !errors.Is(err, domain.ErrDuplicateCLABE) {
+		t.Fatalf("want ErrDuplicateCLABE got %v", err)
+	}
+	err := repository.Do(ctx, func(ctx context.Context) error {
+		return repository.Create(ctx, duplicate)
+	})
+	if !errors.Is(err, domain.ErrDuplicateCLABE) {
+		t.Fatalf("unit of work: want ErrDuplicateCLABE got %v", err)
+	}
+
+	found, err := repository.ByCLABE(ctx, "032180000118359719")
+	if err != nil || found.ID != "acc-1" {
+		t.Fatalf("by clabe: %v %v", found, err)
+	}
+	if _, err := repository.ByCLABE(ctx, "002180000118359710"); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Fatalf("want ErrAccountNotFound got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"sync"
+)
+
+// TransferRepository is an in-memory transfer store (thread-safe).
+// Writes join the caller's unit of work. Saves are checked against the
+// stored Version like accounts: units of work are serialized, so a check made
+// while staging still holds when the write is applied at commit.
+type TransferRepository struct {
+	mutex     sync.RWMutex
+	order     []string // transfer IDs in creation order
+	data      map[string]*domain.Transfer
+	bySPEIKey map[domain.SPEIKey]string // SPEI transfers -> transfer ID
+}
+
+func NewTransferRepo() *TransferRepository {
+	return &TransferRepository{
+		data:      make(map[string]*domain.Transfer),
+		bySPEIKey: make(map[domain.SPEIKey]string),
+	}
+}
+
+func (repository *TransferRepository) TransferByID(ctx context.Context, id string) (*domain.Transfer, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+	transfer, exists := repository.data[id]
+	if !exists {
+		return nil, domain.ErrTransferNotFound
+	}
+	return cloneTransfer(transfer), nil
+}
+
+func (repository *TransferRepository) TransferBySPEIKey(ctx context.Context, key domain.SPEIKey) (*domain.Transfer, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+	transferID, exists := repository.bySPEIKey[key]
+	if !exists {
+		return nil, domain.ErrTransferNotFound
+	}
+	return cloneTransfer(repository.data[transferID]), nil
+}
+
+func (repository *TransferRepository) PendingTransfers(ctx context.Context, limit int) ([]*domain.Transfer, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+	var pending []*domain.Transfer
+	for _, transferID := range repository.order {
+		if len(pending) == limit {
+			break
+		}
+		if transfer := repository.data[transferID]; transfer.Status() == domain.TransferPending {
+			pending = append(pending, cloneTransfer(transfer))
+		}
+	}
+	return pending, nil
+}
+
+func (repository *TransferRepository) CreateTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	key, isSPEI := transfer.SPEIKey()
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	_, exists := repository.data[transfer.ID]
+	_, tracked := repository.bySPEIKey[key]
+	if exists || (isSPEI && tracked) {
+		return errors.New("already exists")
+	}
+	if tx := transactionFrom(ctx); tx != nil {
+		tx.setTransferVersion(transfer, 1)
+		stored := cloneTransfer(transfer)
+		tx.onCommit = append(tx.onCommit, func() { repository.put(stored, true) })
+		return nil
+	}
+	transfer.Version = 1
+	repository.insert(cloneTransfer(transfer), true)
+	return nil
+}
+
+// SaveTransfer stores transfer if its Version matches the stored one and
+// bumps transfer.Version; stale writes get a TransferVersionConflictError.
+func (repository *TransferRepository) SaveTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	var actual int64
+	stored, exists := repository.data[transfer.ID]
+	if exists {
+		actual = stored.Version
+	}
+	tx := transactionFrom(ctx)
+	if tx != nil {
+		if staged, ok := tx.transfers[transfer.ID]; ok {
+			actual, exists = staged, true
+		}
+	}
+	if !exists {
+		return domain.ErrTransferNotFound
+	}
+	if actual != transfer.Version {
+		return &domain.TransferVersionConflictError{TransferID: transfer.ID, ExpectedVersion: transfer.Version, ActualVersion: actual}
+	}
+	if tx != nil {
+		tx.setTransferVersion(transfer, transfer.Version+1)
+		saved := cloneTransfer(transfer)
+		tx.onCommit = append(tx.onCommit, func() { repository.put(saved, false) })
+		return nil
+	}
+	transfer.Version++
+	repository.insert(cloneTransfer(transfer), false)
+	return nil
+}
+
+func (repository *TransferRepository) put(transfer *domain.Transfer, created bool) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.insert(transfer, created)
+}
+
+// insert writes transfer; the caller holds the mutex.
+func (repository *TransferRepository) insert(transfer *domain.Transfer, created bool) {
+	if created {
+		repository.order = append(repository.order, transfer.ID)
+		if key, isSPEI := transfer.SPEIKey(); isSPEI {
+			repository.bySPEIKey[key] = transfer.ID
+		}
+	}
+	repository.data[transfer.ID] = transfer
+}
+
+func cloneTransfer(transfer *domain.Transfer) *domain.Transfer {
+	copy := *transfer
+	return &copy
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.TransferReader = (*TransferRepository)(nil)
+var _ ports.TransferWriter = (*TransferRepository)(nil)
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestSaveTransferRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	accounts, transfers := NewAccountRepo(), NewTransferRepo()
+	now := time.Now()
+	beneficiary, _ := domain.NewBeneficiary("012180000118359713", "Carol")
+	transfer, _ := domain.NewOutboundTransfer("t-1", "acc-1", beneficiary, domain.PaymentDetails{Concept: "rent", Reference: 1}, mxn(100), now)
+	if err := transfers.CreateTransfer(ctx, transfer); err != nil || transfer.Version != 1 {
+		t.Fatalf("create: version=%d %v", transfer.Version, err)
+	}
+
+	worker, _ := transfers.TransferByID(ctx, "t-1")
+	callback, _ := transfers.TransferByID(ctx, "t-1")
+	_ = worker.MarkSent(now)
+	if err := transfers.SaveTransfer(ctx, worker); err != nil {
+		t.Fatalf("sent: %v", err)
+	}
+	_ = callback.MarkSent(now)
+	err := transfers.SaveTransfer(ctx, callback)
+	var conflict *domain.TransferVersionConflictError
+	if !errors.Is(err, domain.ErrVersionConflict) || !errors.As(err, &conflict) || conflict.ExpectedVersion != 1 || conflict.ActualVersion != 2 {
+		t.Fatalf("want version conflict got %v", err)
+	}
+
+	// Inside a unit of work the check runs against staged writes, and a
+	// rollback gives the caller its version back.
+	failure := errors.New("boom")
+	err = accounts.Do(ctx, func(ctx context.Context) error {
+		_ = worker.MarkSettled(now)
+		if err := transfers.SaveTransfer(ctx, worker); err != nil {
+			return err
+		}
+		stale, _ := transfers.TransferByID(ctx, "t-1")
+		if err := transfers.SaveTransfer(ctx, stale); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Fatalf("a second save from the stored copy must conflict, got %v", err)
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) || worker.Version != 2 {
+		t.Fatalf("want the version restored to 2 got %d (%v)", worker.Version, err)
+	}
+	if stored, _ := transfers.TransferByID(ctx, "t-1"); stored.Status() != domain.TransferSent {
+		t.Fatalf("rolled back: want SENT got %s", stored.Status())
+	}
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+)
+
+type transactionKey struct{}
+
+// transaction stages writes made inside AccountRepository.Do.
+// Nothing touches the shared maps until commit, so a failed unit of work
+// simply drops the transaction (rollback).
+type transaction struct {
+	accounts map[string]*domain.Account // staged saves/creates by ID
+	created  map[string]bool            // IDs staged through Create
+	expected map[string]int64           // ID -> stored version the first Save was based on
+	onCommit []func()                   // writes enlisted by other repositories
+	bumped   []func()                   // restore callers' versions if it does not commit
+
+	transfers map[string]int64 // transfer ID -> version staged by SaveTransfer/CreateTransfer
+}
+
+func transactionFrom(ctx context.Context) *transaction {
+	tx, _ := ctx.Value(transactionKey{}).(*transaction)
+	return tx
+}
+
+// enlist defers a write of another in-memory repository until commit.
+// It returns false when ctx carries no transaction (write immediately).
+func enlist(ctx context.Context, write func()) bool {
+	tx := transactionFrom(ctx)
+	if tx == nil {
+		return false
+	}
+	tx.onCommit = append(tx.onCommit, write)
+	return true
+}
+
+// stageSave bumps the version once per unit of work; later saves of the same
+// account must carry the staged version. The bump is undone if the unit of
+// work does not commit.
+func (tx *transaction) stageSave(account *domain.Account) error {
+	if staged, exists := tx.accounts[account.ID]; exists {
+		if staged.Version != account.Version {
+			return &domain.VersionConflictError{AccountID: account.ID, ExpectedVersion: account.Version, ActualVersion: staged.Version}
+		}
+	} else {
+		tx.expected[account.ID] = account.Version
+		tx.setVersion(account, account.Version+1)
+	}
+	tx.accounts[account.ID] = cloneAccount(account)
+	return nil
+}
+
+// stageCreate checks CLABE uniqueness among staged creates; commit checks
+// it again against stored accounts.
+func (tx *transaction) stageCreate(account *domain.Account) error {
+	if _, staged := tx.accounts[account.ID]; staged {
+		return errors.New("already exists")
+	}
+	for accountID := range tx.created {
+		if tx.accounts[accountID].CLABE() == account.CLABE() {
+			return duplicateCLABE(account)
+		}
+	}
+	tx.setVersion(account, 1)
+	tx.accounts[account.ID] = cloneAccount(account)
+	tx.created[account.ID] = true
+	return nil
+}
+
+// setVersion sets account.Version, restoring the current one on rollback.
+func (tx *transaction) setVersion(account *domain.Account, version int64) {
+	previous := account.Version
+	tx.bumped = append(tx.bumped, func() { account.Version = previous })
+	account.Version = version
+}
+
+// setTransferVersion sets transfer.Version, restoring the current one on
+// rollback.
+func (tx *transaction) setTransferVersion(transfer *domain.Transfer, version int64) {
+	previous := transfer.Version
+	tx.bumped = append(tx.bumped, func() { transfer.Version = previous })
+	transfer.Version = version
+	tx.transfers[transfer.ID] = version
+}
+
+func (tx *transaction) rollback() {
+	for i := len(tx.bumped) - 1; i >= 0; i-- {
+		tx.bumped[i]()
+	}
+}
+
+// Do runs fn as a unit of work: account writes (and writes of repositories
+// that enlist, like the ledger) are committed together only if fn succeeds.
+// Units of work are serialized; a nested Do joins the outer one.
+func (repository *AccountRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactionFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	repository.txMutex.Lock()
+	defer repository.txMutex.Unlock()
+
+	tx := &transaction{
+		accounts:  make(map[string]*domain.Account),
+		created:   make(map[string]bool),
+		expected:  make(map[string]int64),
+		transfers: make(map[string]int64),
+	}
+	if err := fn(context.WithValue(ctx, transactionKey{}, tx)); err != nil {
+		tx.rollback() // staged writes are discarded
+		return err
+	}
+	if err := repository.commit(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+func (repository *AccountRepository) commit(tx *transaction) error {
+	repository.mutex.Lock()
+	// Validate everything before applying anything (all-or-nothing).
+	for accountID := range tx.created {
+		if _, exists := repository.data[accountID]; exists {
+			repository.mutex.Unlock()
+			return errors.New("already exists")
+		}
+		if _, taken := repository.byCLABE[tx.accounts[accountID].CLABE()]; taken {
+			repository.mutex.Unlock()
+			return duplicateCLABE(tx.accounts[accountID])
+		}
+	}
+	for accountID, expectedVersion := range tx.expected {
+		stored, exists := repository.data[accountID]
+		if !exists {
+			repository.mutex.Unlock()
+			return domain.ErrAccountNotFound
+		}
+		if stored.Version != expectedVersion {
+			repository.mutex.Unlock()
+			return &domain.VersionConflictError{AccountID: accountID, ExpectedVersion: expectedVersion, ActualVersion: stored.Version}
+		}
+	}
+	for accountID, account := range tx.accounts {
+		repository.data[accountID] = account
+		if tx.created[accountID] {
+			repository.byCLABE[account.CLABE()] = accountID
+		}
+	}
+	repository.mutex.Unlock()
+
+	for _, write := range tx.onCommit {
+		write()
+	}
+	return nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.UnitOfWork = (*AccountRepository)(nil)
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"testing"
+	"time"
+)
+
+func TestUnitOfWorkRollsBackEverything(t *testing.T) {
+	ctx := context.Background()
+	accounts := NewAccountRepo()
+	journal := NewLedgerRepo()
+
+	clabe, _ := domain.NewCLABE("032180000118359719")
+	account, _ := domain.NewAccount("acc-1", "Alice", clabe, domain.MXN)
+	if err := accounts.Create(ctx, account); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	failure := errors.New("boom")
+	var staged *domain.Account
+	err := accounts.Do(ctx, func(ctx context.Context) error {
+		staged, _ = accounts.ByID(ctx, "acc-1")
+		_ = staged.Credit(mxn(700))
+		if err := accounts.Save(ctx, staged); err != nil {
+			return err
+		}
+		entry, _ := ledger.NewEntry("e-1", ledger.KindDeposit, time.Now(),
+			ledger.DebitLeg(ledger.CashAccount, mxn(700)), ledger.CreditLeg("acc-1", mxn(700)))
+		if err := journal.Post(ctx, entry); err != nil {
+			return err
+		}
+		if reread, _ := accounts.ByID(ctx, "acc-1"); reread.Balance().Amount() != 700 {
+			t.Fatalf("staged write not visible inside unit of work")
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("want boom got %v", err)
+	}
+	if staged.Version != 1 {
+		t.Fatalf("a rolled back save keeps the stored version, got %d", staged.Version)
+	}
+
+	stored, _ := accounts.ByID(ctx, "acc-1")
+	if stored.Balance().Amount() != 0 {
+		t.Fatalf("rollback: want=0 got=%d", stored.Balance().Amount())
+	}
+	if balance, _ := journal.BalanceOf(ctx, "acc-1"); balance != 0 {
+		t.Fatalf("rollback ledger: want=0 got=%d", balance)
+	}
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"strings"
+)
+
+// searchCursor is the position after the last account of a page: its sort key
+// and ID (IDs break ties, so the order is total). Keys compare byte-wise
+// (COLLATE "C"), like the in-memory adapter.
+type searchCursor struct {
+	SortBy  ports.AccountSort `json:"s"`
+	Name    string            `json:"n,omitempty"`
+	Balance int64             `json:"b,omitempty"`
+	ID      string            `json:"i"`
+}
+
+// SearchAccounts filters accounts, orders them and returns the page after
+// filter.Cursor. Accounts and their holds are read from one snapshot.
+func (repository *AccountRepository) SearchAccounts(ctx context.Context, filter ports.AccountFilter) (ports.AccountPage, error) {
+	sortBy := cmp.Or(filter.SortBy, ports.SortByID)
+	var sortKey string
+	switch sortBy {
+	case ports.SortByID:
+		sortKey = `id COLLATE "C"`
+	case ports.SortByHolderName:
+		sortKey = `lower(holder_name) COLLATE "C"`
+	case ports.SortByBalance:
+		sortKey = `balance`
+	default:
+		return ports.AccountPage{}, fmt.Errorf("%w: cannot sort by %q", ports.ErrInvalidFilter, sortBy)
+	}
+
+	query := newSearchQuery(filter)
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.SortBy != sortBy {
+			return ports.AccountPage{}, ports.ErrInvalidCursor
+		}
+		query.after(sortBy, sortKey, cursor, filter.Descending)
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	statement := selectAccount + query.where() + ` ORDER BY ` + sortKey + ` ` + direction
+	if sortBy != ports.SortByID {
+		statement += `, id COLLATE "C" ` + direction
+	}
+	if filter.Limit > 0 {
+		statement += fmt.Sprintf(` LIMIT %d`, filter.Limit+1) // one more tells whether a next page exists
+	}
+
+	var page ports.AccountPage
+	err := repository.snapshot(ctx, func(conn executor) error {
+		rows, err := conn.QueryContext(ctx, statement, query.args...)
+		if err != nil {
+			return translate(err)
+		}
+		defer rows.Close()
+		var matches []accountRow
+		for rows.Next() {
+			var row accountRow
+			if err := row.scan(rows); err != nil {
+				return err
+			}
+			matches = append(matches, row)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if filter.Limit > 0 && len(matches) > filter.Limit {
+			matches = matches[:filter.Limit]
+			page.NextCursor = encodeCursor(cursorOf(matches[len(matches)-1], sortBy))
+		}
+
+		ids := make([]string, len(matches))
+		for i, row := range matches {
+			ids[i] = row.id
+		}
+		holds, err := readHolds(ctx, conn, ids...)
+		if err != nil {
+			return err
+		}
+		page.Accounts = make([]*domain.Account, 0, len(matches))
+		for _, row := range matches {
+			account, err := row.restore(holds[row.id])
+			if err != nil {
+				return err
+			}
+			page.Accounts = append(page.Accounts, account)
+		}
+		return nil
+	})
+	if err != nil {
+		return ports.AccountPage{}, err
+	}
+	return page, nil
+}
+
+// searchQuery accumulates the WHERE conditions of a search and their
+// arguments.
+type searchQuery struct {
+	conditions []string
+	args       []any
+}
+
+func newSearchQuery(filter ports.AccountFilter) *searchQuery {
+	query := &searchQuery{}
+	if filter.HolderName != "" {
+		query.add(`strpos(lower(holder_name), lower(%s)) > 0`, filter.HolderName)
+	}
+	if filter.CLABEPrefix != "" {
+		query.add(`starts_with(clabe, %s)`, filter.CLABEPrefix)
+	}
+	if filter.Status != "" {
+		query.add(`status = %s`, string(filter.Status))
+	}
+	if filter.Currency != "" {
+		query.add(`currency = %s`, string(filter.Currency))
+	}
+	if filter.MinBalance != nil {
+		query.add(`balance >= %s`, *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		query.add(`balance <= %s`, *filter.MaxBalance)
+	}
+	return query
+}
+
+// add appends condition, whose %s placeholders take args in order.
+func (query *searchQuery) add(condition string, args ...any) {
+	placeholders := make([]any, len(args))
+	for i, arg := range args {
+		query.args = append(query.args, arg)
+		placeholders[i] = fmt.Sprintf("$%d", len(query.args))
+	}
+	query.conditions = append(query.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// after keeps the accounts that come after cursor in the sort order.
+func (query *searchQuery) after(sortBy ports.AccountSort, sortKey string, cursor searchCursor, descending bool) {
+	operator := ">"
+	if descending {
+		operator = "<"
+	}
+	switch sortBy {
+	case ports.SortByHolderName:
+		query.add(`(`+sortKey+`, id COLLATE "C") `+operator+` (%s, %s)`, cursor.Name, cursor.ID)
+	case ports.SortByBalance:
+		query.add(`(`+sortKey+`, id COLLATE "C") `+operator+` (%s, %s)`, cursor.Balance, cursor.ID)
+	default:
+		query.add(sortKey+` `+operator+` %s`, cursor.ID)
+	}
+}
+
+func (query *searchQuery) where() string {
+	if len(query.conditions) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(query.conditions, ` AND `)
+}
+
+func cursorOf(row accountRow, sortBy ports.AccountSort) searchCursor {
+	cursor := searchCursor{SortBy: sortBy, ID: row.id}
+	switch sortBy {
+	case ports.SortByHolderName:
+		cursor.Name = strings.ToLower(row.holderName)
+	case ports.SortByBalance:
+		cursor.Balance = row.balance
+	}
+	return cursor
+}
+
+func encodeCursor(cursor searchCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (searchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return searchCursor{}, err
+	}
+	var cursor searchCursor
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.AccountQuery = (*AccountRepository)(nil)
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"slices"
+	"testing"
+)
+
+func TestSearchAccountsPaginatesWithCursor(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo(openTestDB(t))
+	seed := []struct {
+		id, holder, clabe string
+		balance           int64
+	}{
+		{"acc-1", "Alice", "032180000118359719", 500},
+		{"acc-2", "Bob", "002180000118359710", 100},
+		{"acc-3", "alicia", "032180000118359706", 300},
+		{"acc-4", "Carol", "012180000118359713", 300},
+		{"acc-5", "Dave", "072180000118359711", 0},
+	}
+	for _, s := range seed {
+		clabe, _ := domain.NewCLABE(s.clabe)
+		account, _ := domain.NewAccount(s.id, s.holder, clabe, domain.MXN)
+		if s.balance > 0 {
+			_ = account.Credit(mxn(s.balance))
+		}
+		_ = account.PlaceHold("h-"+s.id, mxn(s.balance/2))
+		if err := repository.Create(ctx, account); err != nil {
+			t.Fatalf("create %s: %v", s.id, err)
+		}
+	}
+
+	search := func(filter ports.AccountFilter) []string {
+		t.Helper()
+		var got []string
+		for pages := 0; ; pages++ {
+			page, err := repository.SearchAccounts(ctx, filter)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			for _, account := range page.Accounts {
+				got = append(got, account.ID)
+			}
+			if page.NextCursor == "" {
+				return got
+			}
+			if pages > len(seed) {
+				t.Fatalf("cursor does not advance")
+			}
+			filter.Cursor = page.NextCursor
+		}
+	}
+
+	// Balance descending, ties broken by ID; names compare case-insensitively.
+	if got, want := search(ports.AccountFilter{SortBy: ports.SortByBalance, Descending: true, Limit: 2}), []string{"acc-1", "acc-4", "acc-3", "acc-2", "acc-5"}; !slices.Equal(got, want) {
+		t.Fatalf("by balance: want %v got %v", want, got)
+	}
+	if got, want := search(ports.AccountFilter{SortBy: ports.SortByHolderName, Limit: 2}), []string{"acc-1", "acc-3", "acc-2", "acc-4", "acc-5"}; !slices.Equal(got, want) {
+		t.Fatalf("by name: want %v got %v", want, got)
+	}
+
+	minBalance := int64(100)
+	page, _ := repository.SearchAccounts(ctx, ports.AccountFilter{HolderName: "ALI", CLABEPrefix: "032", MinBalance: &minBalance})
+	if len(page.Accounts) != 2 || page.Accounts[0].ID != "acc-1" || page.Accounts[1].ID != "acc-3" {
+		t.Fatalf("filtered search got %v", page.Accounts)
+	}
+	if available := page.Accounts[0].AvailableBalance().Amount(); available != 250 {
+		t.Fatalf("search restores holds: want available 250 got %d", available)
+	}
+
+	// Cursors are only valid for the order they were issued for.
+	page, _ = repository.SearchAccounts(ctx, ports.AccountFilter{Limit: 1})
+	if _, err := repository.SearchAccounts(ctx, ports.AccountFilter{SortBy: ports.SortByBalance, Cursor: page.NextCursor}); !errors.Is(err, ports.ErrInvalidCursor) {
+		t.Fatalf("want ErrInvalidCursor got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+
+	"github.com/lib/pq"
+)
+
+// AccountRepository stores accounts in PostgreSQL through database/sql; db
+// must be opened with lib/pq (driver name "postgres"), which reports the
+// constraints a write violated. It is also the unit of work (see
+// unit_of_work.go). Run Migrate before using it.
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepo(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+const selectAccount = `SELECT id, version, holder_name, clabe, currency, status, balance FROM accounts`
+
+// accountsCLABEKey is the unique index PostgreSQL named for accounts.clabe
+// (0001_create_accounts.sql).
+const accountsCLABEKey = "accounts_clabe_key"
+
+func (repository *AccountRepository) ByID(ctx context.Context, id string) (*domain.Account, error) {
+	return repository.load(ctx, selectAccount+` WHERE id = $1`, id)
+}
+
+func (repository *AccountRepository) ByCLABE(ctx context.Context, clabe string) (*domain.Account, error) {
+	return repository.load(ctx, selectAccount+` WHERE clabe = $1`, clabe)
+}
+
+// load reads one account and its holds. Inside a unit of work the row stays
+// locked until the unit of work ends, and holds only change under that lock.
+// Outside one, both are read from a single snapshot, so a concurrent commit
+// cannot pair the row with other holds.
+func (repository *AccountRepository) load(ctx context.Context, query string, key string) (*domain.Account, error) {
+	if transactionFrom(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+	var account *domain.Account
+	err := repository.snapshot(ctx, func(conn executor) (err error) {
+		account, err = readAccount(ctx, conn, query, key)
+		return err
+	})
+	return account, err
+}
+
+// snapshot runs fn in ctx's unit of work, or else in a read-only transaction
+// so every statement of fn sees the same snapshot.
+func (repository *AccountRepository) snapshot(ctx context.Context, fn func(conn executor) error) error {
+	if tx := transactionFrom(ctx); tx != nil {
+		return fn(tx)
+	}
+	tx, err := repository.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return translate(err)
+	}
+	defer tx.Rollback() // nothing to commit
+	return fn(tx)
+}
+
+func readAccount(ctx context.Context, conn executor, query string, key string) (*domain.Account, error) {
+	var row accountRow
+	err := row.scan(conn.QueryRowContext(ctx, query, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+	holds, err := readHolds(ctx, conn, row.id)
+	if err != nil {
+		return nil, err
+	}
+	return row.restore(holds[row.id])
+}
+
+// accountRow is a row of selectAccount.
+type accountRow struct {
+	id, holderName, clabe, currency, status string
+	version, balance                        int64
+}
+
+func (row *accountRow) scan(scanner interface{ Scan(dest ...any) error }) error {
+	return scanner.Scan(&row.id, &row.version, &row.holderName, &row.clabe, &row.currency, &row.status, &row.balance)
+}
+
+// restore builds the account from row and its holds (hold ID -> minor units).
+func (row accountRow) restore(holds map[string]int64) (*domain.Account, error) {
+	parsedCLABE, err := domain.NewCLABE(row.clabe)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", row.id, err)
+	}
+	money, err := domain.NewMoney(row.balance, domain.Currency(row.currency))
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", row.id, err)
+	}
+	held := make(map[string]domain.Money, len(holds))
+	for holdID, amount := range holds {
+		if held[holdID], err = domain.NewMoney(amount, money.Currency()); err != nil {
+			return nil, fmt.Errorf("account %s hold %s: %w", row.id, holdID, err)
+		}
+	}
+	return domain.RestoreAccount(row.id, row.version, row.holderName, parsedCLABE, domain.AccountStatus(row.status), money, held)
+}
+
+// readHolds reads the holds of accountIDs: account ID -> hold ID -> amount.
+func readHolds(ctx context.Context, conn executor, accountIDs ...string) (map[string]map[string]int64, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT account_id, hold_id, amount FROM account_holds WHERE account_id = ANY($1)`, pq.Array(accountIDs))
+	if err != nil {
+		return nil, translate(err)
+	}
+	defer rows.Close()
+	holds := make(map[string]map[string]int64)
+	for rows.Next() {
+		var (
+			accountID, holdID string
+			amount            int64
+		)
+		if err := rows.Scan(&accountID, &holdID, &amount); err != nil {
+			return nil, err
+		}
+		if holds[accountID] == nil {
+			holds[accountID] = make(map[string]int64)
+		}
+		holds[accountID][holdID] = amount
+	}
+	return holds, rows.Err()
+}
+
+// Save stores account if its Version matches the stored one (optimistic
+// concurrency) and bumps account.Version; stale writes get a VersionConflictError.
+// The account row and its holds are written in one transaction; the bump is
+// undone if that transaction (or the enclosing unit of work) does not commit.
+func (repository *AccountRepository) Save(ctx context.Context, account *domain.Account) error {
+	return repository.Do(ctx, func(ctx context.Context) error {
+		tx := transactionFrom(ctx)
+		result, err := tx.ExecContext(ctx,
+			`UPDATE accounts SET version = version + 1, holder_name = $3, status = $4, balance = $5, updated_at = now()
+			 WHERE id = $1 AND version = $2`,
+			account.ID, account.Version, account.HolderName(), string(account.Status()), account.Balance().Amount())
+		if err != nil {
+			return translate(err)
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			return repository.staleOrMissing(ctx, tx, account, err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM account_holds WHERE account_id = $1`, account.ID); err != nil {
+			return translate(err)
+		}
+		if err := insertHolds(ctx, tx, account); err != nil {
+			return err
+		}
+		tx.setVersion(&account.Version, account.Version+1)
+		return nil
+	})
+}
+
+// staleOrMissing explains why an UPDATE matched no row.
+func (repository *AccountRepository) staleOrMissing(ctx context.Context, tx executor, account *domain.Account, err error) error {
+	if err != nil {
+		return err
+	}
+	var actual int64
+	err = tx.QueryRowContext(ctx, `SELECT version FROM accounts WHERE id = $1`, account.ID).Scan(&actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return translate(err)
+	}
+	return &domain.VersionConflictError{AccountID: account.ID, ExpectedVersion: account.Version, ActualVersion: actual}
+}
+
+// Create stores a new account at version 1; CLABEs are unique. Other
+// violations (e.g. a reused ID) are returned as they are.
+func (repository *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	return repository.Do(ctx, func(ctx context.Context) error {
+		tx := transactionFrom(ctx)
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO accounts (id, version, holder_name, clabe, currency, status, balance) VALUES ($1, 1, $2, $3, $4, $5, $6)`,
+			account.ID, account.HolderName(), account.CLABE(), string(account.Currency()), string(account.Status()), account.Balance().Amount())
+		if sqlState(err) == uniqueViolation && constraintOf(err) == accountsCLABEKey {
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateCLABE, account.CLABE())
+		}
+		if err != nil {
+			return translate(err)
+		}
+		if err := insertHolds(ctx, tx, account); err != nil {
+			return err
+		}
+		tx.setVersion(&account.Version, 1)
+		return nil
+	})
+}
+
+func insertHolds(ctx context.Context, tx executor, account *domain.Account) error {
+	for holdID, amount := range account.Holds() {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO account_holds (account_id, hold_id, amount) VALUES ($1, $2, $3)`,
+			account.ID, holdID, amount.Amount()); err != nil {
+			return translate(err)
+		}
+	}
+	return nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.AccountReader = (*AccountRepository)(nil)
+var _ ports.AccountWriter = (*AccountRepository)(nil)
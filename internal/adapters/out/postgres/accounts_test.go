@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openTestDB connects to the scratch database in POSTGRES_TEST_DSN, migrated
+// and emptied. Without it the test is skipped, except in CI (which sets CI),
+// where a missing database must not pass unnoticed.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" && os.Getenv("CI") != "" {
+		t.Fatalf("POSTGRES_TEST_DSN is not set in CI")
+	}
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	for range 2 { // migrating twice is a no-op
+		if err := Migrate(ctx, db); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `TRUNCATE accounts, account_holds, journal_entries, journal_legs, transfers, outbox_messages`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	return db
+}
+
+func mxn(cents int64) domain.Money {
+	money, _ := domain.NewMoney(cents, domain.MXN)
+	return money
+}
+
+func createAccount(t *testing.T, repository *AccountRepository, id, clabe string) *domain.Account {
+	t.Helper()
+	parsed, _ := domain.NewCLABE(clabe)
+	account, _ := domain.NewAccount(id, "Alice", parsed, domain.MXN)
+	if err := repository.Create(context.Background(), account); err != nil {
+		t.Fatalf("create %s: %v", id, err)
+	}
+	return account
+}
+
+func TestAccountRepositoryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo(openTestDB(t))
+	account := createAccount(t, repository, "acc-1", "032180000118359719")
+
+	_ = account.Credit(mxn(1000))
+	_ = account.PlaceHold("h-1", mxn(300))
+	if err := repository.Save(ctx, account); err != nil || account.Version != 2 {
+		t.Fatalf("save: want version 2 got %d %v", account.Version, err)
+	}
+
+	stored, err := repository.ByCLABE(ctx, "032180000118359719")
+	if err != nil {
+		t.Fatalf("by clabe: %v", err)
+	}
+	if stored.Version != 2 || stored.Balance().Amount() != 1000 || stored.AvailableBalance().Amount() != 700 {
+		t.Fatalf("round trip: got %s", stored)
+	}
+	if _, err := repository.ByID(ctx, "missing"); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Fatalf("want ErrAccountNotFound got %v", err)
+	}
+}
+
+func TestAccountRepositoryRejectsStaleAndDuplicateWrites(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo(openTestDB(t))
+	createAccount(t, repository, "acc-1", "032180000118359719")
+
+	first, _ := repository.ByID(ctx, "acc-1")
+	second, _ := repository.ByID(ctx, "acc-1")
+	_ = first.Credit(mxn(100))
+	if err := repository.Save(ctx, first); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	_ = second.Credit(mxn(200))
+	var conflict *domain.VersionConflictError
+	if err := repository.Save(ctx, second); !errors.As(err, &conflict) || conflict.ActualVersion != 2 {
+		t.Fatalf("want a version conflict at version 2 got %v", err)
+	}
+
+	parsed, _ := domain.NewCLABE("032180000118359719")
+	duplicate, _ := domain.NewAccount("acc-2", "Bob", parsed, domain.MXN)
+	if err := repository.Create(ctx, duplicate); !errors.Is(err, domain.ErrDuplicateCLABE) {
+		t.Fatalf("want ErrDuplicateCLABE got %v", err)
+	}
+	parsed, _ = domain.NewCLABE("002180000118359710")
+	reusedID, _ := domain.NewAccount("acc-1", "Bob", parsed, domain.MXN)
+	if err := repository.Create(ctx, reusedID); err == nil || errors.Is(err, domain.ErrDuplicateCLABE) {
+		t.Fatalf("a reused ID is not a duplicate CLABE, got %v", err)
+	}
+}
+
+func TestUnitOfWorkRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo(openTestDB(t))
+	createAccount(t, repository, "acc-1", "032180000118359719")
+
+	failure := errors.New("boom")
+	var staged *domain.Account
+	err := repository.Do(ctx, func(ctx context.Context) error {
+		staged, _ = repository.ByID(ctx, "acc-1")
+		_ = staged.Credit(mxn(700))
+		if err := repository.Save(ctx, staged); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("want boom got %v", err)
+	}
+	if staged.Version != 1 {
+		t.Fatalf("a rolled back save keeps the stored version, got %d", staged.Version)
+	}
+	if stored, _ := repository.ByID(ctx, "acc-1"); stored.Balance().Amount() != 0 || stored.Version != 1 {
+		t.Fatalf("rollback: got %s", stored)
+	}
+}
+
+func TestUnitOfWorkLocksAccountRows(t *testing.T) {
+	ctx := context.Background()
+	repository := NewAccountRepo(openTestDB(t))
+	createAccount(t, repository, "acc-1", "032180000118359719")
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repository.Do(ctx, func(ctx context.Context) error {
+				account, err := repository.ByID(ctx, "acc-1")
+				if err != nil {
+					return err
+				}
+				_ = account.Credit(mxn(1))
+				return repository.Save(ctx, account)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("locked read-modify-write must not conflict, got %v", err)
+		}
+	}
+	if stored, _ := repository.ByID(ctx, "acc-1"); stored.Balance().Amount() != workers {
+		t.Fatalf("want balance %d got %s", workers, stored)
+	}
+}
+
+func TestUnitOfWorkSpansTransfersLedgerAndOutbox(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	accounts, transfers, journal, outbox := NewAccountRepo(db), NewTransferRepo(db), NewLedgerRepo(db), NewOutbox(db)
+	createAccount(t, accounts, "acc-1", "032180000118359719")
+	now := time.Now()
+
+	failure := errors.New("boom")
+	var transfer *domain.Transfer
+	err := accounts.Do(ctx, func(ctx context.Context) error {
+		account, _ := accounts.ByID(ctx, "acc-1")
+		_ = account.Credit(mxn(700))
+		if err := accounts.Save(ctx, account); err != nil {
+			return err
+		}
+		transfer, _ = domain.NewTransfer("t-1", "acc-2", "acc-1", mxn(700), now)
+		if err := transfers.CreateTransfer(ctx, transfer); err != nil {
+			return err
+		}
+		entry, _ := ledger.NewEntry("e-1", ledger.KindTransfer, now, ledger.DebitLeg("acc-2", mxn(700)), ledger.CreditLeg("acc-1", mxn(700)))
+		if err := journal.Post(ctx, entry); err != nil {
+			return err
+		}
+		if balance, _ := journal.BalanceOf(ctx, "acc-1"); balance != 700 {
+			t.Fatalf("the unit of work must see its own posts, got %d", balance)
+		}
+		if err := outbox.Enqueue(ctx, "transfer.settled", transfer.ID); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("want boom got %v", err)
+	}
+	if transfer.Version != 0 {
+		t.Fatalf("a rolled back create keeps the transfer unsaved, got version %d", transfer.Version)
+	}
+	if _, err := transfers.TransferByID(ctx, "t-1"); !errors.Is(err, domain.ErrTransferNotFound) {
+		t.Fatalf("rollback transfers: want ErrTransferNotFound got %v", err)
+	}
+	if balance, _ := journal.BalanceOf(ctx, "acc-1"); balance != 0 {
+		t.Fatalf("rollback ledger: want 0 got %d", balance)
+	}
+	if due, _ := outbox.Due(ctx, now.Add(time.Minute), 10); len(due) != 0 {
+		t.Fatalf("rollback outbox: want nothing due got %d", len(due))
+	}
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+)
+
+// LedgerRepository is the append-only journal in PostgreSQL. Posts join the
+// caller's unit of work (see AccountRepository.Do).
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepo(db *sql.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// Post appends entry and its legs in one transaction.
+func (repository *LedgerRepository) Post(ctx context.Context, entry ledger.Entry) error {
+	return do(ctx, repository.db, func(ctx context.Context) error {
+		tx := transactionFrom(ctx)
+		if _, err := tx.ExecContext(ctx, `INSERT INTO journal_entries (id, kind, posted_at) VALUES ($1, $2, $3)`,
+			entry.ID, entry.Kind, entry.PostedAt); err != nil {
+			return translate(err)
+		}
+		for position, leg := range entry.Legs {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO journal_legs (entry_id, position, account_id, amount, currency) VALUES ($1, $2, $3, $4, $5)`,
+				entry.ID, position, leg.AccountID, leg.Amount, string(leg.Currency)); err != nil {
+				return translate(err)
+			}
+		}
+		return nil
+	})
+}
+
+// BalanceOf sums the legs posted to accountID. Inside a unit of work it sees
+// the unit of work's own posts.
+func (repository *LedgerRepository) BalanceOf(ctx context.Context, accountID string) (int64, error) {
+	var balance int64
+	err := executorFor(ctx, repository.db).QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM journal_legs WHERE account_id = $1`, accountID).Scan(&balance)
+	return balance, translate(err)
+}
+
+// Transactions reads the entries touching accountID in posting order, in one
+// statement, and pages their statement lines newest first.
+func (repository *LedgerRepository) Transactions(ctx context.Context, accountID string, offset, limit int) ([]ledger.Line, int, error) {
+	rows, err := executorFor(ctx, repository.db).QueryContext(ctx,
+		`SELECT e.id, e.kind, e.posted_at, l.account_id, l.amount, l.currency
+		 FROM journal_entries e JOIN journal_legs l ON l.entry_id = e.id
+		 WHERE e.id IN (SELECT entry_id FROM journal_legs WHERE account_id = $1)
+		 ORDER BY e.sequence, l.position`, accountID)
+	if err != nil {
+		return nil, 0, translate(err)
+	}
+	defer rows.Close()
+	var entries []ledger.Entry
+	for rows.Next() {
+		var (
+			entry    ledger.Entry
+			leg      ledger.Leg
+			currency string
+		)
+		if err := rows.Scan(&entry.ID, &entry.Kind, &entry.PostedAt, &leg.AccountID, &leg.Amount, &currency); err != nil {
+			return nil, 0, err
+		}
+		leg.Currency = domain.Currency(currency)
+		if last := len(entries) - 1; last >= 0 && entries[last].ID == entry.ID {
+			entries[last].Legs = append(entries[last].Legs, leg)
+			continue
+		}
+		entry.PostedAt = entry.PostedAt.UTC()
+		entry.Legs = []ledger.Leg{leg}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	lines := ledger.Statement(accountID, entries)
+	total := len(lines)
+	// Newest first: walk the chronological statement backwards.
+	page := make([]ledger.Line, 0, limit)
+	for position := total - 1 - offset; position >= 0 && len(page) < limit; position-- {
+		page = append(page, lines[position])
+	}
+	return page, total, nil
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.LedgerReader = (*LedgerRepository)(nil)
+var _ ports.LedgerWriter = (*LedgerRepository)(nil)
+var _ ports.TransactionReader = (*LedgerRepository)(nil)
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"testing"
+	"time"
+)
+
+func TestLedgerRepositoryStatement(t *testing.T) {
+	ctx := context.Background()
+	journal := NewLedgerRepo(openTestDB(t))
+	now := time.Now()
+
+	entries := []struct {
+		id   string
+		kind string
+		legs []ledger.Leg
+	}{
+		{"e-1", ledger.KindDeposit, []ledger.Leg{ledger.DebitLeg(ledger.CashAccount, mxn(1000)), ledger.CreditLeg("acc-1", mxn(1000))}},
+		{"e-2", ledger.KindTransfer, []ledger.Leg{ledger.DebitLeg("acc-1", mxn(300)), ledger.CreditLeg("acc-2", mxn(300))}},
+		{"e-3", ledger.KindDeposit, []ledger.Leg{ledger.DebitLeg(ledger.CashAccount, mxn(50)), ledger.CreditLeg("acc-2", mxn(50))}},
+		{"e-4", ledger.KindWithdrawal, []ledger.Leg{ledger.DebitLeg("acc-1", mxn(200)), ledger.CreditLeg(ledger.CashAccount, mxn(200))}},
+	}
+	for i, e := range entries {
+		entry, err := ledger.NewEntry(e.id, e.kind, now.Add(time.Duration(i)*time.Second), e.legs...)
+		if err != nil {
+			t.Fatalf("entry %s: %v", e.id, err)
+		}
+		if err := journal.Post(ctx, entry); err != nil {
+			t.Fatalf("post %s: %v", e.id, err)
+		}
+	}
+
+	if balance, err := journal.BalanceOf(ctx, "acc-1"); err != nil || balance != 500 {
+		t.Fatalf("balance: want 500 got %d %v", balance, err)
+	}
+	if balance, _ := journal.BalanceOf(ctx, "acc-unknown"); balance != 0 {
+		t.Fatalf("an account without legs has balance 0, got %d", balance)
+	}
+
+	// Newest first, skipping e-3 which does not touch acc-1.
+	page, total, err := journal.Transactions(ctx, "acc-1", 1, 5)
+	if err != nil || total != 3 || len(page) != 2 {
+		t.Fatalf("statement: want 2 of 3 lines got %d of %d %v", len(page), total, err)
+	}
+	if page[0].EntryID != "e-2" || page[0].Amount != -300 || page[0].RunningBalance != 700 || page[0].Counterparty != "acc-2" {
+		t.Fatalf("statement line: got %+v", page[0])
+	}
+	if page[1].EntryID != "e-1" || page[1].RunningBalance != 1000 {
+		t.Fatalf("statement line: got %+v", page[1])
+	}
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock key serializing migrators ("hexbank").
+const migrationLock = 0x6865_7862_616e_6b
+
+type migration struct {
+	version int
+	name    string // file name, e.g. "0001_create_accounts.sql"
+	sql     string
+}
+
+// Migrate applies the embedded migrations that are not applied yet, in
+// version order, each in its own transaction. An advisory lock keeps
+// instances starting together from applying the same migration twice.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+	pending, err := migrations()
+	if err != nil {
+		return err
+	}
+	for _, next := range pending {
+		if err := apply(ctx, db, next); err != nil {
+			return fmt.Errorf("migration %s: %w", next.name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, next migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLock); err != nil {
+		return err
+	}
+	var applied bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, next.version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, next.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, next.version, next.name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrations lists the embedded files "<version>_<description>.sql" in
+// version order; versions must be unique.
+func migrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	var list []migration
+	for _, entry := range entries { // sorted by file name
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<description>.sql", entry.Name())
+		}
+		if len(list) > 0 && list[len(list)-1].version >= version {
+			return nil, fmt.Errorf("migration %s: version %d is not after %d", entry.Name(), version, list[len(list)-1].version)
+		}
+		raw, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, migration{version: version, name: entry.Name(), sql: string(raw)})
+	}
+	return list, nil
+}
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedMigrationsAreOrdered(t *testing.T) {
+	list, err := migrations()
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if len(list) == 0 || list[0].version != 1 {
+		t.Fatalf("want migrations starting at version 1 got %+v", list)
+	}
+	for _, next := range list {
+		if strings.TrimSpace(next.sql) == "" {
+			t.Fatalf("migration %s is empty", next.name)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+-- Accounts with optimistic concurrency (version) and their holds.
+CREATE TABLE accounts (
+    id          TEXT PRIMARY KEY,
+    version     BIGINT NOT NULL,
+    holder_name TEXT NOT NULL CHECK (btrim(holder_name) <> ''),
+    clabe       CHAR(18) NOT NULL UNIQUE,
+    currency    CHAR(3) NOT NULL,
+    status      TEXT NOT NULL CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    balance     BIGINT NOT NULL CHECK (balance >= 0), -- minor units
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Funds reserved by in-flight transfers, keyed by transfer ID.
+CREATE TABLE account_holds (
+    account_id TEXT NOT NULL REFERENCES accounts (id),
+    hold_id    TEXT NOT NULL,
+    amount     BIGINT NOT NULL CHECK (amount > 0), -- minor units, account currency
+    PRIMARY KEY (account_id, hold_id)
+);
@@ -0,0 +1,19 @@
+-- Append-only double-entry journal; sequence is the posting order.
+CREATE TABLE journal_entries (
+    sequence  BIGSERIAL PRIMARY KEY,
+    id        TEXT NOT NULL UNIQUE,
+    kind      TEXT NOT NULL,
+    posted_at TIMESTAMPTZ NOT NULL
+);
+
+-- Legs are signed from the account holder's point of view: positive credits.
+CREATE TABLE journal_legs (
+    entry_id   TEXT NOT NULL REFERENCES journal_entries (id),
+    position   INTEGER NOT NULL,
+    account_id TEXT NOT NULL,
+    amount     BIGINT NOT NULL CHECK (amount <> 0), -- minor units
+    currency   CHAR(3) NOT NULL,
+    PRIMARY KEY (entry_id, position)
+);
+
+CREATE INDEX journal_legs_account ON journal_legs (account_id);
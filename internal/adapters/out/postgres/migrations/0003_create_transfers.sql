@@ -0,0 +1,44 @@
+-- Transfers with optimistic concurrency (version). Fields a kind does not use
+-- are empty strings, zeros or NULL times.
+CREATE TABLE transfers (
+    id                  TEXT PRIMARY KEY,
+    version             BIGINT NOT NULL,
+    kind                TEXT NOT NULL CHECK (kind IN ('INTERNAL', 'SPEI_OUT', 'SPEI_IN')),
+    from_id             TEXT NOT NULL,
+    to_id               TEXT NOT NULL,
+    beneficiary_clabe   TEXT NOT NULL,
+    beneficiary_name    TEXT NOT NULL,
+    originator_clabe    TEXT NOT NULL,
+    originator_name     TEXT NOT NULL,
+    originator_bank     TEXT NOT NULL,
+    tracking_key        TEXT NOT NULL,
+    operation_date      DATE,
+    concept             TEXT NOT NULL,
+    reference           INTEGER NOT NULL,
+    amount              BIGINT NOT NULL CHECK (amount > 0), -- minor units of currency
+    currency            CHAR(3) NOT NULL,
+    credit_amount       BIGINT NOT NULL CHECK (credit_amount > 0),
+    credit_currency     CHAR(3) NOT NULL,
+    quote_id            TEXT, -- NULL unless converted
+    quote_from          CHAR(3),
+    quote_to            CHAR(3),
+    quote_rate_micros   BIGINT,
+    quote_expires_at    TIMESTAMPTZ,
+    status              TEXT NOT NULL CHECK (status IN ('PENDING', 'SENT', 'SETTLED', 'FAILED', 'REVERSED', 'RETURNED')),
+    failure_reason      TEXT NOT NULL,
+    rail_reference      TEXT NOT NULL,
+    rejection_code      TEXT, -- NULL unless the rail refused the last attempt
+    rejection_retryable BOOLEAN,
+    rejection_reason    TEXT,
+    attempts            INTEGER NOT NULL CHECK (attempts >= 0),
+    accepted_at         TIMESTAMPTZ,
+    settled_at          TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL,
+    updated_at          TIMESTAMPTZ NOT NULL
+);
+
+-- A SPEI payment is recorded once (see domain.SPEIKey).
+CREATE UNIQUE INDEX transfers_outbound_spei_key ON transfers (tracking_key) WHERE kind = 'SPEI_OUT';
+CREATE UNIQUE INDEX transfers_inbound_spei_key ON transfers (originator_bank, tracking_key, operation_date) WHERE kind = 'SPEI_IN';
+
+CREATE INDEX transfers_pending ON transfers (created_at, id) WHERE status = 'PENDING';
@@ -0,0 +1,13 @@
+-- Transactional outbox: events written with the change that raised them.
+CREATE TABLE outbox_messages (
+    sequence        BIGSERIAL PRIMARY KEY,
+    id              TEXT NOT NULL UNIQUE,
+    topic           TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX outbox_messages_due ON outbox_messages (next_attempt_at, sequence);
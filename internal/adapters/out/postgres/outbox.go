@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/shared/id"
+	"time"
+)
+
+// Outbox is the transactional outbox in PostgreSQL: Enqueue joins the
+// caller's unit of work (see AccountRepository.Do), so a message is stored
+// only if the change that raised it commits. Payloads are stored as JSON and
+// come back from Due as json.RawMessage.
+type Outbox struct {
+	db *sql.DB
+}
+
+func NewOutbox(db *sql.DB) *Outbox {
+	return &Outbox{db: db}
+}
+
+func (outbox *Outbox) Enqueue(ctx context.Context, topic string, payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("outbox %s payload: %w", topic, err)
+	}
+	now := time.Now().UTC()
+	_, err = executorFor(ctx, outbox.db).ExecContext(ctx,
+		`INSERT INTO outbox_messages (id, topic, payload, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $4)`,
+		id.New(), topic, string(encoded), now)
+	return translate(err)
+}
+
+func (outbox *Outbox) Due(ctx context.Context, now time.Time, limit int) ([]ports.OutboxMessage, error) {
+	rows, err := executorFor(ctx, outbox.db).QueryContext(ctx,
+		`SELECT id, topic, payload, attempts, next_attempt_at, last_error, created_at FROM outbox_messages
+		 WHERE next_attempt_at <= $1 ORDER BY sequence LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, translate(err)
+	}
+	defer rows.Close()
+	var due []ports.OutboxMessage
+	for rows.Next() {
+		var (
+			message ports.OutboxMessage
+			payload []byte
+		)
+		if err := rows.Scan(&message.ID, &message.Topic, &payload, &message.Attempts, &message.NextAttemptAt, &message.LastError, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+		message.Payload = json.RawMessage(payload)
+		message.NextAttemptAt, message.CreatedAt = message.NextAttemptAt.UTC(), message.CreatedAt.UTC()
+		due = append(due, message)
+	}
+	return due, rows.Err()
+}
+
+func (outbox *Outbox) MarkPublished(ctx context.Context, messageID string) error {
+	_, err := executorFor(ctx, outbox.db).ExecContext(ctx, `DELETE FROM outbox_messages WHERE id = $1`, messageID)
+	return translate(err)
+}
+
+func (outbox *Outbox) ScheduleRetry(ctx context.Context, messageID string, nextAttemptAt time.Time, lastError string) error {
+	_, err := executorFor(ctx, outbox.db).ExecContext(ctx,
+		`UPDATE outbox_messages SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1`,
+		messageID, nextAttemptAt, lastError)
+	return translate(err)
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.Outbox = (*Outbox)(nil)
+var _ ports.OutboxRelayStore = (*Outbox)(nil)
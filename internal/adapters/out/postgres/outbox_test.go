@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestOutboxRelaysMessagesOldestFirst(t *testing.T) {
+	ctx := context.Background()
+	outbox := NewOutbox(openTestDB(t))
+
+	for _, id := range []string{"t-1", "t-2"} {
+		if err := outbox.Enqueue(ctx, "transfer.settled", map[string]string{"transferId": id}); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	due, err := outbox.Due(ctx, time.Now().Add(time.Second), 10)
+	if err != nil || len(due) != 2 {
+		t.Fatalf("due: want 2 got %d %v", len(due), err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(due[0].Payload.(json.RawMessage), &payload); err != nil || payload["transferId"] != "t-1" {
+		t.Fatalf("want t-1 first got %s %v", due[0].Payload, err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := outbox.ScheduleRetry(ctx, due[0].ID, later, "broker down"); err != nil {
+		t.Fatalf("schedule retry: %v", err)
+	}
+	if err := outbox.MarkPublished(ctx, due[1].ID); err != nil {
+		t.Fatalf("mark published: %v", err)
+	}
+	if due, _ := outbox.Due(ctx, time.Now().Add(time.Second), 10); len(due) != 0 {
+		t.Fatalf("a retry waits for its next attempt, got %d due", len(due))
+	}
+	due, _ = outbox.Due(ctx, later, 10)
+	if len(due) != 1 || due[0].Attempts != 1 || due[0].LastError != "broker down" {
+		t.Fatalf("retry: got %+v", due)
+	}
+}
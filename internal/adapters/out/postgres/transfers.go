@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"strings"
+	"time"
+)
+
+// TransferRepository stores transfers in PostgreSQL. Writes join the caller's
+// unit of work (see AccountRepository.Do), and transfers read inside one stay
+// locked until it ends, like accounts. Saves are checked against the stored
+// Version.
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepo(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// transferFields are the columns after id and version, in the order of
+// transferValues.
+const transferFields = `kind, from_id, to_id, beneficiary_clabe, beneficiary_name,
+	originator_clabe, originator_name, originator_bank, tracking_key, operation_date, concept, reference,
+	amount, currency, credit_amount, credit_currency,
+	quote_id, quote_from, quote_to, quote_rate_micros, quote_expires_at,
+	status, failure_reason, rail_reference, rejection_code, rejection_retryable, rejection_reason, attempts,
+	accepted_at, settled_at, created_at, updated_at`
+
+const transferFieldCount = 32
+
+const selectTransfer = `SELECT id, version, ` + transferFields + ` FROM transfers`
+
+// Unique indexes on SPEI keys (0003_create_transfers.sql).
+const (
+	transfersOutboundSPEIKey = "transfers_outbound_spei_key"
+	transfersInboundSPEIKey  = "transfers_inbound_spei_key"
+)
+
+func (repository *TransferRepository) TransferByID(ctx context.Context, id string) (*domain.Transfer, error) {
+	return repository.load(ctx, selectTransfer+` WHERE id = $1`, id)
+}
+
+func (repository *TransferRepository) TransferBySPEIKey(ctx context.Context, key domain.SPEIKey) (*domain.Transfer, error) {
+	switch key.Kind {
+	case domain.TransferOutbound:
+		return repository.load(ctx, selectTransfer+` WHERE kind = 'SPEI_OUT' AND tracking_key = $1`, key.TrackingKey)
+	case domain.TransferInbound:
+		return repository.load(ctx, selectTransfer+` WHERE kind = 'SPEI_IN' AND originator_bank = $1 AND tracking_key = $2 AND operation_date = $3`,
+			key.BankCode, key.TrackingKey, nullDate(key.OperationDate))
+	}
+	return nil, domain.ErrTransferNotFound
+}
+
+// load reads one transfer, locking it inside a unit of work.
+func (repository *TransferRepository) load(ctx context.Context, query string, args ...any) (*domain.Transfer, error) {
+	if transactionFrom(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+	transfer, err := scanTransfer(executorFor(ctx, repository.db).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTransferNotFound
+	}
+	return transfer, translate(err)
+}
+
+func (repository *TransferRepository) PendingTransfers(ctx context.Context, limit int) ([]*domain.Transfer, error) {
+	rows, err := executorFor(ctx, repository.db).QueryContext(ctx,
+		selectTransfer+` WHERE status = 'PENDING' ORDER BY created_at, id LIMIT $1`, limit)
+	if err != nil {
+		return nil, translate(err)
+	}
+	defer rows.Close()
+	var pending []*domain.Transfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, transfer)
+	}
+	return pending, rows.Err()
+}
+
+// CreateTransfer stores a new transfer at version 1. A SPEI key recorded
+// meanwhile by a concurrent unit of work is a version conflict, so the use
+// case retries and finds it.
+func (repository *TransferRepository) CreateTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	return do(ctx, repository.db, func(ctx context.Context) error {
+		tx := transactionFrom(ctx)
+		args := append([]any{transfer.ID}, transferValues(transfer.Snapshot())...)
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO transfers (id, version, `+transferFields+`) VALUES ($1, 1, `+placeholders(2, transferFieldCount)+`)`, args...)
+		if sqlState(err) == uniqueViolation {
+			switch constraintOf(err) {
+			case transfersOutboundSPEIKey, transfersInboundSPEIKey:
+				return fmt.Errorf("%w: SPEI transfer %s recorded concurrently", domain.ErrVersionConflict, transfer.TrackingKey())
+			}
+		}
+		if err != nil {
+			return translate(err)
+		}
+		tx.setVersion(&transfer.Version, 1)
+		return nil
+	})
+}
+
+// SaveTransfer stores transfer if its Version matches the stored one and
+// bumps transfer.Version; stale writes get a TransferVersionConflictError.
+// The bump is undone if the enclosing unit of work does not commit.
+func (repository *TransferRepository) SaveTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	return do(ctx, repository.db, func(ctx context.Context) error {
+		tx := transactionFrom(ctx)
+		args := append([]any{transfer.ID, transfer.Version}, transferValues(transfer.Snapshot())...)
+		result, err := tx.ExecContext(ctx,
+			`UPDATE transfers SET version = version + 1, (`+transferFields+`) = (`+placeholders(3, transferFieldCount)+`)
+			 WHERE id = $1 AND version = $2`, args...)
+		if err != nil {
+			return translate(err)
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			return staleOrMissingTransfer(ctx, tx, transfer, err)
+		}
+		tx.setVersion(&transfer.Version, transfer.Version+1)
+		return nil
+	})
+}
+
+// staleOrMissingTransfer explains why an UPDATE matched no row.
+func staleOrMissingTransfer(ctx context.Context, tx executor, transfer *domain.Transfer, err error) error {
+	if err != nil {
+		return err
+	}
+	var actual int64
+	err = tx.QueryRowContext(ctx, `SELECT version FROM transfers WHERE id = $1`, transfer.ID).Scan(&actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrTransferNotFound
+	}
+	if err != nil {
+		return translate(err)
+	}
+	return &domain.TransferVersionConflictError{TransferID: transfer.ID, ExpectedVersion: transfer.Version, ActualVersion: actual}
+}
+
+// transferValues lists snapshot's fields in transferFields order.
+func transferValues(snapshot domain.TransferSnapshot) []any {
+	var quoteID, quoteFrom, quoteTo, quoteRate, quoteExpiresAt any
+	if quote := snapshot.Quote; quote != nil {
+		quoteID, quoteFrom, quoteTo = quote.ID(), string(quote.From()), string(quote.To())
+		quoteRate, quoteExpiresAt = quote.RateMicros(), quote.ExpiresAt()
+	}
+	var rejectionCode, rejectionRetryable, rejectionReason any
+	if rejection := snapshot.Rejection; rejection != nil {
+		rejectionCode, rejectionRetryable, rejectionReason = rejection.Code, rejection.Retryable, rejection.Reason
+	}
+	return []any{
+		string(snapshot.Kind), snapshot.FromID, snapshot.ToID,
+		snapshot.Beneficiary.CLABE().String(), snapshot.Beneficiary.Name(),
+		snapshot.Originator.CLABE, snapshot.Originator.Name, snapshot.Originator.BankCode,
+		snapshot.TrackingKey, nullDate(snapshot.OperationDate), snapshot.Details.Concept, snapshot.Details.Reference,
+		snapshot.Amount.Amount(), string(snapshot.Amount.Currency()),
+		snapshot.CreditAmount.Amount(), string(snapshot.CreditAmount.Currency()),
+		quoteID, quoteFrom, quoteTo, quoteRate, quoteExpiresAt,
+		string(snapshot.Status), snapshot.FailureReason, snapshot.RailReference,
+		rejectionCode, rejectionRetryable, rejectionReason, snapshot.Attempts,
+		nullTime(snapshot.AcceptedAt), nullTime(snapshot.SettledAt), snapshot.CreatedAt, snapshot.UpdatedAt,
+	}
+}
+
+// scanTransfer reads a row of selectTransfer.
+func scanTransfer(row interface{ Scan(dest ...any) error }) (*domain.Transfer, error) {
+	var (
+		snapshot                                                    domain.TransferSnapshot
+		kind, status, beneficiaryCLABE, beneficiaryName             string
+		currency, creditCurrency                                    string
+		amount, creditAmount                                        int64
+		operationDate, quoteExpiresAt, acceptedAt, settledAt        sql.NullTime
+		quoteID, quoteFrom, quoteTo, rejectionCode, rejectionReason sql.NullString
+		quoteRate                                                   sql.NullInt64
+		rejectionRetryable                                          sql.NullBool
+	)
+	err := row.Scan(&snapshot.ID, &snapshot.Version, &kind, &snapshot.FromID, &snapshot.ToID, &beneficiaryCLABE, &beneficiaryName,
+		&snapshot.Originator.CLABE, &snapshot.Originator.Name, &snapshot.Originator.BankCode,
+		&snapshot.TrackingKey, &operationDate, &snapshot.Details.Concept, &snapshot.Details.Reference,
+		&amount, &currency, &creditAmount, &creditCurrency,
+		&quoteID, &quoteFrom, &quoteTo, &quoteRate, &quoteExpiresAt,
+		&status, &snapshot.FailureReason, &snapshot.RailReference,
+		&rejectionCode, &rejectionRetryable, &rejectionReason, &snapshot.Attempts,
+		&acceptedAt, &settledAt, &snapshot.CreatedAt, &snapshot.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Kind, snapshot.Status = domain.TransferKind(kind), domain.TransferStatus(status)
+	if beneficiaryName != "" {
+		if snapshot.Beneficiary, err = domain.NewBeneficiary(beneficiaryCLABE, beneficiaryName); err != nil {
+			return nil, fmt.Errorf("transfer %s: %w", snapshot.ID, err)
+		}
+	}
+	if snapshot.Amount, err = domain.NewMoney(amount, domain.Currency(currency)); err != nil {
+		return nil, fmt.Errorf("transfer %s: %w", snapshot.ID, err)
+	}
+	if snapshot.CreditAmount, err = domain.NewMoney(creditAmount, domain.Currency(creditCurrency)); err != nil {
+		return nil, fmt.Errorf("transfer %s: %w", snapshot.ID, err)
+	}
+	if quoteID.Valid {
+		quote, err := domain.NewFXQuote(quoteID.String, domain.Currency(quoteFrom.String), domain.Currency(quoteTo.String), quoteRate.Int64, quoteExpiresAt.Time.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("transfer %s: %w", snapshot.ID, err)
+		}
+		snapshot.Quote = &quote
+	}
+	if rejectionCode.Valid {
+		snapshot.Rejection = &domain.RailRejection{Code: rejectionCode.String, Retryable: rejectionRetryable.Bool, Reason: rejectionReason.String}
+	}
+	if operationDate.Valid {
+		year, month, day := operationDate.Time.Date()
+		snapshot.OperationDate = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	snapshot.AcceptedAt, snapshot.SettledAt = utc(acceptedAt), utc(settledAt)
+	snapshot.CreatedAt, snapshot.UpdatedAt = snapshot.CreatedAt.UTC(), snapshot.UpdatedAt.UTC()
+	return domain.RestoreTransfer(snapshot)
+}
+
+// placeholders returns "$from, ..., $(from+count-1)".
+func placeholders(from, count int) string {
+	list := make([]string, count)
+	for i := range list {
+		list[i] = fmt.Sprintf("$%d", from+i)
+	}
+	return strings.Join(list, ", ")
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullDate stores t's calendar day, or NULL for a zero time.
+func nullDate(t time.Time) sql.NullString {
+	return sql.NullString{String: t.Format(time.DateOnly), Valid: !t.IsZero()}
+}
+
+func utc(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.UTC()
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.TransferReader = (*TransferRepository)(nil)
+var _ ports.TransferWriter = (*TransferRepository)(nil)
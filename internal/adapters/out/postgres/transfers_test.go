@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransferRepositoryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	transfers := NewTransferRepo(openTestDB(t))
+	now := time.Now().UTC().Truncate(time.Microsecond) // PostgreSQL keeps microseconds
+
+	beneficiary, _ := domain.NewBeneficiary("012180000118359713", "Carol")
+	outbound, _ := domain.NewOutboundTransfer("t-1", "acc-1", beneficiary, domain.PaymentDetails{Concept: "rent", Reference: 7}, mxn(100), now)
+	_ = outbound.MarkSent(now)
+	_ = outbound.MarkRejected(domain.RailRejection{Code: "INSUFFICIENT_FUNDS", Retryable: true, Reason: "try later"}, now)
+	operationDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	originator := domain.Originator{CLABE: "002180000118359710", Name: "Bob", BankCode: "002"}
+	inbound, _ := domain.NewInboundTransfer("t-2", "TRACK-1", operationDate, "acc-1", originator, domain.PaymentDetails{Concept: "gift"}, mxn(50), now)
+	_ = inbound.MarkSettled(now)
+	for _, transfer := range []*domain.Transfer{outbound, inbound} {
+		if err := transfers.CreateTransfer(ctx, transfer); err != nil || transfer.Version != 1 {
+			t.Fatalf("create %s: version=%d %v", transfer.ID, transfer.Version, err)
+		}
+	}
+
+	stored, err := transfers.TransferByID(ctx, "t-1")
+	if err != nil {
+		t.Fatalf("by id: %v", err)
+	}
+	if !reflect.DeepEqual(stored.Snapshot(), outbound.Snapshot()) {
+		t.Fatalf("round trip:\nwant %+v\n got %+v", outbound.Snapshot(), stored.Snapshot())
+	}
+	key, _ := inbound.SPEIKey()
+	stored, err = transfers.TransferBySPEIKey(ctx, key)
+	if err != nil || !reflect.DeepEqual(stored.Snapshot(), inbound.Snapshot()) {
+		t.Fatalf("by SPEI key: got %v %v", stored, err)
+	}
+	if _, err := transfers.TransferBySPEIKey(ctx, domain.InboundSPEIKey("002", "TRACK-1", operationDate.AddDate(0, 0, 1))); !errors.Is(err, domain.ErrTransferNotFound) {
+		t.Fatalf("another operation date is another payment, got %v", err)
+	}
+
+	pending, err := transfers.PendingTransfers(ctx, 10)
+	if err != nil || len(pending) != 1 || pending[0].ID != "t-1" {
+		t.Fatalf("pending: want [t-1] got %v %v", pending, err)
+	}
+}
+
+func TestTransferRepositoryRejectsStaleAndDuplicateWrites(t *testing.T) {
+	ctx := context.Background()
+	transfers := NewTransferRepo(openTestDB(t))
+	now := time.Now()
+	beneficiary, _ := domain.NewBeneficiary("012180000118359713", "Carol")
+	transfer, _ := domain.NewOutboundTransfer("t-1", "acc-1", beneficiary, domain.PaymentDetails{Concept: "rent", Reference: 1}, mxn(100), now)
+	if err := transfers.CreateTransfer(ctx, transfer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	worker, _ := transfers.TransferByID(ctx, "t-1")
+	callback, _ := transfers.TransferByID(ctx, "t-1")
+	_ = worker.MarkSent(now)
+	if err := transfers.SaveTransfer(ctx, worker); err != nil || worker.Version != 2 {
+		t.Fatalf("sent: version=%d %v", worker.Version, err)
+	}
+	_ = callback.MarkSent(now)
+	var conflict *domain.TransferVersionConflictError
+	if err := transfers.SaveTransfer(ctx, callback); !errors.As(err, &conflict) || conflict.ActualVersion != 2 {
+		t.Fatalf("want a version conflict at version 2 got %v", err)
+	}
+
+	// A redelivered inbound payment under another ID is the same SPEI payment.
+	operationDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	originator := domain.Originator{CLABE: "002180000118359710", Name: "Bob", BankCode: "002"}
+	first, _ := domain.NewInboundTransfer("t-2", "TRACK-1", operationDate, "acc-1", originator, domain.PaymentDetails{}, mxn(50), now)
+	redelivered, _ := domain.NewInboundTransfer("t-3", "TRACK-1", operationDate, "acc-1", originator, domain.PaymentDetails{}, mxn(50), now)
+	if err := transfers.CreateTransfer(ctx, first); err != nil {
+		t.Fatalf("create inbound: %v", err)
+	}
+	if err := transfers.CreateTransfer(ctx, redelivered); !errors.Is(err, domain.ErrVersionConflict) || redelivered.Version != 0 {
+		t.Fatalf("want a version conflict for a recorded SPEI key got version=%d %v", redelivered.Version, err)
+	}
+}
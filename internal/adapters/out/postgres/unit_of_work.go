@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+
+	"github.com/lib/pq"
+)
+
+type transactionKey struct{}
+
+// executor is what *sql.DB and *sql.Tx have in common.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// transaction is a unit of work in progress.
+type transaction struct {
+	*sql.Tx
+	onRollback []func() // undo in-memory changes (e.g. version bumps) if it does not commit
+}
+
+func transactionFrom(ctx context.Context) *transaction {
+	tx, _ := ctx.Value(transactionKey{}).(*transaction)
+	return tx
+}
+
+// Do runs fn in a database transaction, committed only if fn succeeds. The
+// transfer, ledger and outbox repositories of this package write through the
+// same transaction when given fn's ctx.
+// Accounts read inside it are locked (SELECT ... FOR UPDATE) until it ends,
+// so concurrent units of work on the same accounts wait for each other
+// instead of failing with version conflicts. A nested Do joins the outer one.
+// If the transaction does not commit, the accounts and transfers it saved get
+// their previous Version back.
+func (repository *AccountRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return do(ctx, repository.db, fn)
+}
+
+// do runs fn in ctx's transaction, or in a new one on db.
+func do(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if transactionFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	sqlTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx := &transaction{Tx: sqlTx}
+	if err := fn(context.WithValue(ctx, transactionKey{}, tx)); err != nil {
+		_ = sqlTx.Rollback()
+		tx.rollback()
+		return translate(err)
+	}
+	if err := sqlTx.Commit(); err != nil {
+		tx.rollback()
+		return translate(err)
+	}
+	return nil
+}
+
+// setVersion sets *version (an account's or transfer's Version), restoring
+// the current value on rollback.
+func (tx *transaction) setVersion(version *int64, to int64) {
+	previous := *version
+	tx.onRollback = append(tx.onRollback, func() { *version = previous })
+	*version = to
+}
+
+// executorFor runs statements in ctx's unit of work, if any, or on db.
+func executorFor(ctx context.Context, db *sql.DB) executor {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx
+	}
+	return db
+}
+
+func (tx *transaction) rollback() {
+	for i := len(tx.onRollback) - 1; i >= 0; i-- {
+		tx.onRollback[i]()
+	}
+}
+
+// PostgreSQL error codes (SQLSTATE) the adapter translates.
+const (
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// sqlState reads the SQLSTATE of a driver error.
+func sqlState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+// constraintOf names the constraint a driver error violated, if any.
+func constraintOf(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
+
+// translate turns lock contention PostgreSQL resolved by aborting us into a
+// version conflict, which use cases already retry.
+func translate(err error) error {
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return err // already translated
+	}
+	switch sqlState(err) {
+	case serializationFailure, deadlockDetected:
+		return fmt.Errorf("%w: %w", domain.ErrVersionConflict, err)
+	}
+	return err
+}
+
+// Ensure interface compliance (at compile-time).
+var _ ports.UnitOfWork = (*AccountRepository)(nil)
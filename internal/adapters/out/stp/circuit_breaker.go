@@ -0,0 +1,73 @@
+package stp
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/circuitbreaker"
+	"hexagonal-bank/internal/platform/logging"
+)
+
+// CircuitBreaker decorates a PaymentGateway: after FailureThreshold calls in
+// a row fail transiently (the wrapped gateway's own retries included) it stops
+// calling the rail and answers *ports.GatewayUnavailableError at once, until a
+// trial call after the cool-down succeeds.
+//
+// Permanent rejections do not count: a rail that rejects an order is up.
+type CircuitBreaker struct {
+	gateway ports.PaymentGateway
+	breaker *circuitbreaker.Breaker
+}
+
+// NewCircuitBreaker wraps gateway. settings.IsFailure defaults to "anything
+// but a permanent rejection or a duplicate order", both answers from STP.
+func NewCircuitBreaker(logger logging.Logger, gateway ports.PaymentGateway, settings circuitbreaker.Settings) *CircuitBreaker {
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool { return !errors.Is(err, ports.ErrPaymentRejected) && !duplicateOrder(err) }
+	}
+	if settings.OnStateChange == nil {
+		settings.OnStateChange = func(from, to circuitbreaker.State) {
+			logger.Warn("payment gateway circuit changed", "from", from, "to", to)
+		}
+	}
+	return &CircuitBreaker{gateway: gateway, breaker: circuitbreaker.New(settings)}
+}
+
+func (decorator *CircuitBreaker) SendTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	return decorator.call(ctx, func(ctx context.Context) (ports.PaymentResult, error) {
+		return decorator.gateway.SendTransfer(ctx, order)
+	})
+}
+
+func (decorator *CircuitBreaker) ReverseTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	return decorator.call(ctx, func(ctx context.Context) (ports.PaymentResult, error) {
+		return decorator.gateway.ReverseTransfer(ctx, order)
+	})
+}
+
+// Health reports the breaker state, an open circuit meaning "do not send".
+func (decorator *CircuitBreaker) Health() ports.GatewayHealth {
+	snapshot := decorator.breaker.Snapshot()
+	return ports.GatewayHealth{
+		State:               ports.CircuitState(snapshot.State),
+		ConsecutiveFailures: snapshot.ConsecutiveFailures,
+		RetryAfter:          snapshot.RetryAfter,
+	}
+}
+
+func (decorator *CircuitBreaker) call(ctx context.Context, fn func(ctx context.Context) (ports.PaymentResult, error)) (ports.PaymentResult, error) {
+	var result ports.PaymentResult
+	err := decorator.breaker.Call(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	var open *circuitbreaker.OpenError
+	if errors.As(err, &open) {
+		return ports.PaymentResult{}, &ports.GatewayUnavailableError{RetryAfter: open.RetryAfter}
+	}
+	return result, err
+}
+
+var _ ports.PaymentGateway = (*CircuitBreaker)(nil)
+var _ ports.GatewayMonitor = (*CircuitBreaker)(nil)
@@ -0,0 +1,47 @@
+package stp
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/circuitbreaker"
+	"hexagonal-bank/internal/platform/logging"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOnlyCountsTransientFailures(t *testing.T) {
+	var slept []time.Duration
+	config := testConfig(1, &slept)
+	config.FailureRate = 0
+	config.Retry.MaxAttempts = 1
+	config.Script = []Outcome{
+		Reject(ports.RejectInvalidAccount, "cuenta inexistente"), Reject(ports.RejectDuplicateOrder, "Clave de rastreo duplicada"),
+		Unavailable, Unavailable,
+	}
+	gateway := NewCircuitBreaker(logging.NewStd(), NewFakeSTP(logging.NewStd(), config), circuitbreaker.Settings{FailureThreshold: 2, CoolDown: time.Minute})
+	ctx := context.Background()
+
+	if _, err := gateway.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t"}); !errors.Is(err, ports.ErrPaymentRejected) {
+		t.Fatalf("want permanent rejection got %v", err)
+	}
+	if _, err := gateway.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t"}); !errors.Is(err, ports.ErrPaymentOutcomeUnknown) {
+		t.Fatalf("want duplicate order got %v", err)
+	}
+	if health := gateway.Health(); health.State != ports.CircuitClosed {
+		t.Fatalf("answers from STP keep the circuit closed, got %+v", health)
+	}
+
+	for range 2 {
+		gateway.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t"})
+	}
+	calls := len(slept)
+	_, err := gateway.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t"})
+	var unavailable *ports.GatewayUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.RetryAfter <= 0 || len(slept) != calls {
+		t.Fatalf("want a fast GatewayUnavailableError got %v (%d calls to STP)", err, len(slept)-calls)
+	}
+	if health := gateway.Health(); health.State != ports.CircuitOpen {
+		t.Fatalf("want OPEN got %+v", health)
+	}
+}
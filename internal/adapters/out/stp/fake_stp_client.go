@@ -1,50 +1,200 @@
 package stp
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hexagonal-bank/internal/core/application/ports"
 	"hexagonal-bank/internal/platform/backoff"
 	"hexagonal-bank/internal/platform/logging"
 	"math/rand"
+	"net/http"
+	"sync"
 	"time"
 )
 
 // FakeSTP simulates a flaky external API and uses retry + backoff with jitter.
+// Its behavior (failure rate, latency, scripted outcomes) comes from a
+// FakeSTPConfig.
+//
+// By default an accepted order is settled at once. With a CallbackURL it
+// behaves like real SPEI: orders are only accepted, and the outcome is POSTed
+// later to the status webhook, signed with the shared secret. Some orders
+// come back returned ("DEVUELTA").
 type FakeSTP struct {
-	logger logging.Logger
-}
-
-func NewFakeSTP(logger logging.Logger) *FakeSTP { return &FakeSTP{logger: logger} }
-
-func (client *FakeSTP) SendTransfer(ctx context.Context, fromID, toID string, cents int64) (string, error) {
-	const (
-		maxRetries = 4
-		baseDelay  = 200 * time.Millisecond
-		multiplier = 2.0
-		maxDelay   = 3 * time.Second
-	)
-	for attemptIndex := 0; attemptIndex <= maxRetries; attemptIndex++ {
-		// 70% success simulation; 30% transient failure
-		if rand.Float64() < 0.7 {
-			return "OK", nil
-		}
-		// transient error
-		transientErr := errors.New("temporary STP outage")
-		if attemptIndex == maxRetries {
-			client.logger.Error("STP failed after retries", "from", fromID, "to", toID, "err", transientErr)
-			return "FAILED", transientErr
+	logger    logging.Logger
+	config    FakeSTPConfig
+	retrier   *backoff.Retrier // STP calls
+	redeliver *backoff.Retrier // status callbacks
+	client    *http.Client
+
+	mutex    sync.Mutex // guards config.Random and the script position
+	scriptAt int
+	orders   int
+}
+
+// NewFakeSTP builds the fake from config; a nil Random or Clock takes the
+// default (seeded from the clock, and the system clock).
+func NewFakeSTP(logger logging.Logger, config FakeSTPConfig) *FakeSTP {
+	if config.Random == nil {
+		config.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if config.Clock == nil {
+		config.Clock = backoff.SystemClock{}
+	}
+	client := &FakeSTP{logger: logger, config: config, client: &http.Client{Timeout: 5 * time.Second}}
+
+	// Only STP's transient rejections are worth sending again.
+	retry := config.Retry
+	retry.Classifier = func(err error) bool {
+		var rejection *ports.PaymentError
+		return errors.As(err, &rejection) && rejection.Retryable() && !duplicateOrder(err)
+	}
+	retry.Clock = config.Clock
+	retry.Random = rand.New(rand.NewSource(config.Random.Int63())) // reproducible, not shared
+	retry.OnRetry = func(attemptIndex int, delay time.Duration, err error) {
+		logger.Warn("STP transient error, retrying", "attempt", attemptIndex, "sleep", delay, "err", err)
+	}
+	client.retrier = backoff.NewRetrier(retry)
+
+	// STP redelivers status callbacks until the webhook answers 200.
+	client.redeliver = backoff.NewRetrier(backoff.Policy{
+		MaxAttempts: 5,
+		Strategy:    backoff.FullJitterBackoff(500*time.Millisecond, 2.0, 10*time.Second),
+		Clock:       config.Clock,
+		Random:      rand.New(rand.NewSource(config.Random.Int63())),
+		OnRetry: func(attemptIndex int, delay time.Duration, err error) {
+			logger.Warn("STP status callback failed, retrying", "attempt", attemptIndex, "sleep", delay, "err", err)
+		},
+	})
+	return client
+}
+
+func (client *FakeSTP) SendTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	if err := client.call(ctx, "transfer", order); err != nil {
+		return ports.PaymentResult{}, err
+	}
+	result := client.accepted(order)
+	if client.config.CallbackURL != "" {
+		result.Status, result.SettledAt = ports.PaymentAccepted, time.Time{}
+		// Drawn now, in call order, so a seeded run returns the same orders.
+		go client.reportOutcome(order, client.drawReturned())
+	}
+	return result, nil
+}
+
+func (client *FakeSTP) ReverseTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	if err := client.call(ctx, "reversal", order); err != nil {
+		return ports.PaymentResult{}, err
+	}
+	return client.accepted(order), nil
+}
+
+// accepted is a settled result; rail references are sequential, like STP's
+// order IDs.
+func (client *FakeSTP) accepted(order ports.PaymentOrder) ports.PaymentResult {
+	client.mutex.Lock()
+	client.orders++
+	reference := fmt.Sprintf("%d", 3_000_000+client.orders)
+	client.mutex.Unlock()
+	now := time.Now().UTC()
+	return ports.PaymentResult{TrackingKey: order.TrackingKey, RailReference: reference, Status: ports.PaymentSettled, AcceptedAt: now, SettledAt: now}
+}
+
+// call simulates round trips to STP, retrying transient failures.
+func (client *FakeSTP) call(ctx context.Context, operation string, order ports.PaymentOrder) error {
+	err := client.retrier.Do(ctx, func(ctx context.Context) error {
+		latency, outcome := client.nextAttempt()
+		if err := client.config.Clock.Sleep(ctx, latency); err != nil {
+			return err
 		}
-		sleepDuration := backoff.FullJitter(attemptIndex, baseDelay, multiplier, maxDelay)
-		client.logger.Warn("STP transient error, retrying", "attempt", attemptIndex, "sleep", sleepDuration)
-		select {
-		case <-ctx.Done():
-			return "CANCELLED", ctx.Err()
-		case <-time.After(sleepDuration):
+		if outcome.rejection == nil {
+			return nil
 		}
+		return outcome.rejection
+	})
+	if err != nil {
+		client.logger.Error("STP call failed", "operation", operation, "tracking_key", order.TrackingKey, "to", order.BeneficiaryCLABE, "err", err)
+	}
+	return err
+}
+
+// nextAttempt draws the latency and outcome of one attempt: scripted while
+// the script lasts, random afterwards.
+func (client *FakeSTP) nextAttempt() (time.Duration, Outcome) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	config := client.config
+	latency := config.MinLatency
+	if spread := config.MaxLatency - config.MinLatency; spread > 0 {
+		latency += time.Duration(config.Random.Int63n(int64(spread) + 1))
+	}
+	if client.scriptAt < len(config.Script) {
+		client.scriptAt++
+		return latency, config.Script[client.scriptAt-1]
+	}
+	if config.Random.Float64() < config.FailureRate {
+		return latency, Unavailable
+	}
+	return latency, Succeed
+}
+
+// drawReturned decides whether an accepted order will come back returned.
+func (client *FakeSTP) drawReturned() bool {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	return client.config.Random.Float64() < client.config.ReturnRate
+}
+
+// statusChange mirrors STP's "cambio de estado" notification.
+type statusChange struct {
+	TrackingKey string `json:"claveRastreo"`
+	Status      string `json:"estado"`
+	ReturnCause string `json:"causaDevolucion,omitempty"`
+}
+
+// reportOutcome settles or returns the order after the configured delay and
+// notifies the webhook, redelivering (backoff + jitter) until it answers 200
+// like STP does.
+func (client *FakeSTP) reportOutcome(order ports.PaymentOrder, returned bool) {
+	ctx := context.Background()
+	_ = client.config.Clock.Sleep(ctx, client.config.CallbackDelay)
+
+	change := statusChange{TrackingKey: order.TrackingKey, Status: "LIQUIDADA"}
+	if returned {
+		change = statusChange{TrackingKey: order.TrackingKey, Status: "DEVUELTA", ReturnCause: "cuenta inexistente"}
+	}
+	body, _ := json.Marshal(change)
+	if err := client.redeliver.Do(ctx, func(ctx context.Context) error { return client.deliver(ctx, body) }); err != nil {
+		client.logger.Error("STP status callback given up", "tracking_key", order.TrackingKey, "estado", change.Status, "err", err)
+		return
+	}
+	client.logger.Info("STP status callback delivered", "tracking_key", order.TrackingKey, "estado", change.Status)
+}
+
+func (client *FakeSTP) deliver(ctx context.Context, body []byte) error {
+	mac := hmac.New(sha256.New, client.config.CallbackSecret)
+	mac.Write(body)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.config.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-STP-Signature", hex.EncodeToString(mac.Sum(nil)))
+	response, err := client.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook answered %d", response.StatusCode)
 	}
-	return "FAILED", fmt.Errorf("unreachable") // defensive
+	return nil
 }
 
 // Ensure interface compliance
@@ -0,0 +1,159 @@
+package stp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/logging"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingClock records sleeps instead of waiting.
+type recordingClock struct{ slept *[]time.Duration }
+
+func (clock recordingClock) Now() time.Time { return time.Now() }
+
+func (clock recordingClock) Sleep(ctx context.Context, duration time.Duration) error {
+	*clock.slept = append(*clock.slept, duration)
+	return nil
+}
+
+// testConfig is a seeded fake that records its sleeps instead of waiting.
+func testConfig(seed int64, slept *[]time.Duration) FakeSTPConfig {
+	config := DefaultFakeSTPConfig()
+	config.Random = rand.New(rand.NewSource(seed))
+	config.MinLatency, config.MaxLatency = 10*time.Millisecond, 50*time.Millisecond
+	config.Clock = recordingClock{slept: slept}
+	return config
+}
+
+func TestFakeSTPFollowsScript(t *testing.T) {
+	var slept []time.Duration
+	config := testConfig(1, &slept)
+	config.Script = []Outcome{Unavailable, TimedOut, Succeed, Reject(ports.RejectInvalidAccount, "cuenta inexistente"), NameMismatch}
+	config.FailureRate = 0
+	fake := NewFakeSTP(logging.NewStd(), config)
+	ctx := context.Background()
+
+	result, err := fake.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t-1"})
+	if err != nil || result.Status != ports.PaymentSettled || result.TrackingKey != "t-1" || result.RailReference == "" {
+		t.Fatalf("two transient failures are retried: %+v %v", result, err)
+	}
+	if len(slept) != 5 { // three latencies, two backoffs
+		t.Fatalf("want 5 sleeps got %v", slept)
+	}
+
+	_, err = fake.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t-2"})
+	var rejection *ports.PaymentError
+	if !errors.As(err, &rejection) || rejection.Code != ports.RejectInvalidAccount || !errors.Is(err, ports.ErrPaymentRejected) {
+		t.Fatalf("want permanent INVALID_ACCOUNT got %v", err)
+	}
+	if len(slept) != 6 {
+		t.Fatalf("permanent rejections are not retried, sleeps %v", slept)
+	}
+
+	_, err = fake.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t-4"})
+	if !errors.As(err, &rejection) || rejection.Code != ports.RejectBeneficiaryNameMismatch || !errors.Is(err, ports.ErrPaymentRejected) {
+		t.Fatalf("want permanent BENEFICIARY_NAME_MISMATCH got %v", err)
+	}
+	if len(slept) != 7 {
+		t.Fatalf("permanent rejections are not retried, sleeps %v", slept)
+	}
+
+	if _, err := fake.SendTransfer(ctx, ports.PaymentOrder{TrackingKey: "t-3"}); err != nil {
+		t.Fatalf("after the script, FailureRate 0 always succeeds: %v", err)
+	}
+}
+
+func TestFakeSTPGivesUpAfterMaxRetries(t *testing.T) {
+	var slept []time.Duration
+	config := testConfig(1, &slept)
+	config.FailureRate = 1
+	config.Retry.MaxAttempts = 3
+	_, err := NewFakeSTP(logging.NewStd(), config).SendTransfer(context.Background(), ports.PaymentOrder{TrackingKey: "t-1"})
+	if !errors.Is(err, ports.ErrPaymentRetryable) {
+		t.Fatalf("want retryable rejection got %v", err)
+	}
+	if len(slept) != 5 { // three attempts, two backoffs
+		t.Fatalf("want 5 sleeps got %v", slept)
+	}
+}
+
+func TestFakeSTPIsReproducibleWithASeed(t *testing.T) {
+	run := func() ([]bool, []time.Duration) {
+		var slept []time.Duration
+		config := testConfig(42, &slept)
+		config.FailureRate = 0.5
+		config.Retry.MaxAttempts = 2
+		fake := NewFakeSTP(logging.NewStd(), config)
+		var outcomes []bool
+		for range 20 {
+			_, err := fake.SendTransfer(context.Background(), ports.PaymentOrder{TrackingKey: "t"})
+			outcomes = append(outcomes, err == nil)
+		}
+		return outcomes, slept
+	}
+	firstOutcomes, firstSleeps := run()
+	secondOutcomes, secondSleeps := run()
+	if !reflect.DeepEqual(firstOutcomes, secondOutcomes) || !reflect.DeepEqual(firstSleeps, secondSleeps) {
+		t.Fatalf("same seed, different runs:\n%v\n%v", firstOutcomes, secondOutcomes)
+	}
+}
+
+// instantClock never waits.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time                                          { return time.Now() }
+func (instantClock) Sleep(ctx context.Context, duration time.Duration) error { return ctx.Err() }
+
+func TestFakeSTPZeroConfigDoesNotPanic(t *testing.T) {
+	if _, err := NewFakeSTP(logging.NewStd(), FakeSTPConfig{}).SendTransfer(context.Background(), ports.PaymentOrder{TrackingKey: "t-1"}); err != nil {
+		t.Fatalf("a zero config never fails: %v", err)
+	}
+}
+
+func TestFakeSTPCallbacksAreReproducibleWithASeed(t *testing.T) {
+	const orders = 20
+	run := func() map[string]string {
+		var mutex sync.Mutex
+		outcomes := make(map[string]string)
+		delivered := make(chan struct{}, orders)
+		webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var change statusChange
+			_ = json.NewDecoder(r.Body).Decode(&change)
+			mutex.Lock()
+			outcomes[change.TrackingKey] = change.Status
+			mutex.Unlock()
+			delivered <- struct{}{}
+		}))
+		defer webhook.Close()
+
+		config := DefaultFakeSTPConfig()
+		config.Random = rand.New(rand.NewSource(42))
+		config.FailureRate, config.ReturnRate = 0, 0.5
+		config.Clock = instantClock{}
+		config.CallbackURL, config.CallbackSecret = webhook.URL, []byte("secret")
+		fake := NewFakeSTP(logging.NewStd(), config)
+		for order := range orders {
+			if _, err := fake.SendTransfer(context.Background(), ports.PaymentOrder{TrackingKey: fmt.Sprintf("t-%d", order)}); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+		}
+		for range orders {
+			<-delivered
+		}
+		return outcomes
+	}
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("same seed, different callbacks:\n%v\n%v", first, second)
+	}
+}
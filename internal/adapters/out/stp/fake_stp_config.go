@@ -0,0 +1,78 @@
+package stp
+
+import (
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/backoff"
+	"math/rand"
+	"time"
+)
+
+// Outcome is the fake's answer to one attempt: success, or a rejection.
+type Outcome struct {
+	rejection *ports.PaymentError
+}
+
+var (
+	// Succeed makes the attempt go through.
+	Succeed = Outcome{}
+	// Unavailable fails the attempt transiently; the fake retries it.
+	Unavailable = Reject(ports.RejectRailUnavailable, "temporary STP outage")
+	// TimedOut fails the attempt transiently; the fake retries it.
+	TimedOut = Reject(ports.RejectTimeout, "no answer from STP")
+	// NameMismatch rejects the order for good: the beneficiary's name does
+	// not match the account's holder.
+	NameMismatch = Reject(ports.RejectBeneficiaryNameMismatch, "nombre del beneficiario no coincide")
+)
+
+// Reject answers an attempt with code; permanent codes are not retried.
+func Reject(code ports.RejectionCode, reason string) Outcome {
+	return Outcome{rejection: &ports.PaymentError{Code: code, Reason: reason}}
+}
+
+// FakeSTPConfig tunes FakeSTP. Start from DefaultFakeSTPConfig.
+type FakeSTPConfig struct {
+	// Random drives failures, latency, jitter and returns. Seed it for
+	// reproducible runs; it must not be shared with other code. Nil seeds
+	// one from the clock.
+	Random *rand.Rand
+	// Script answers the first attempts (retries included), in order; once
+	// it runs out, attempts fail transiently at FailureRate.
+	Script      []Outcome
+	FailureRate float64
+
+	// Latency of every attempt, uniform in [MinLatency, MaxLatency].
+	MinLatency time.Duration
+	MaxLatency time.Duration
+
+	// Retry governs retries of transient failures. Its Classifier, Clock,
+	// Random and OnRetry are set by the fake.
+	Retry backoff.Policy
+
+	// Clock waits for latency and backoff; replace it to run without waiting.
+	// Nil means backoff.SystemClock.
+	Clock backoff.Clock
+
+	// CallbackURL switches to asynchronous settlement: orders are only
+	// accepted, and their outcome is POSTed to this status webhook (signed
+	// with CallbackSecret) after CallbackDelay. ReturnRate of them come back
+	// returned.
+	CallbackURL    string
+	CallbackSecret []byte
+	CallbackDelay  time.Duration
+	ReturnRate     float64
+}
+
+// DefaultFakeSTPConfig is a flaky rail: 30% of attempts fail transiently and
+// are retried up to 4 times (full jitter: 200ms base delay, doubling, 3s cap).
+func DefaultFakeSTPConfig() FakeSTPConfig {
+	return FakeSTPConfig{
+		Random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		FailureRate: 0.3,
+		Retry: backoff.Policy{
+			MaxAttempts: 5,
+			Strategy:    backoff.FullJitterBackoff(200*time.Millisecond, 2.0, 3*time.Second),
+		},
+		Clock:      backoff.SystemClock{},
+		ReturnRate: 0.1,
+	}
+}
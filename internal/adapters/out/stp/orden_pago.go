@@ -0,0 +1,162 @@
+package stp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"hexagonal-bank/internal/core/application/ports"
+	"strconv"
+	"strings"
+)
+
+// Values STP expects for a SPEI payment between third parties.
+const (
+	stpInstitution        = 90646 // "institucionOperante": STP's Banxico code
+	paymentTypeThirdParty = 1     // "tipoPago": tercero a tercero
+	accountTypeCLABE      = 40    // "tipoCuenta*": CLABE
+	unknownRFC            = "ND"  // "rfcCurp*" when not known
+)
+
+// ordenPago is the registraOrden request body.
+type ordenPago struct {
+	CounterpartInstitution int    `json:"institucionContraparte"` // Banxico code, e.g. 40012
+	Company                string `json:"empresa"`
+	TrackingKey            string `json:"claveRastreo"`
+	OperatingInstitution   int    `json:"institucionOperante"`
+	Amount                 string `json:"monto"` // pesos, two decimals, e.g. "125.50"
+	PaymentType            int    `json:"tipoPago"`
+	OriginAccountType      int    `json:"tipoCuentaOrdenante"`
+	OriginName             string `json:"nombreOrdenante"`
+	OriginCLABE            string `json:"cuentaOrdenante"`
+	OriginRFC              string `json:"rfcCurpOrdenante"`
+	BeneficiaryAccountType int    `json:"tipoCuentaBeneficiario"`
+	BeneficiaryName        string `json:"nombreBeneficiario"`
+	BeneficiaryCLABE       string `json:"cuentaBeneficiario"`
+	BeneficiaryRFC         string `json:"rfcCurpBeneficiario"`
+	Concept                string `json:"conceptoPago"`
+	Reference              int    `json:"referenciaNumerica"`
+	Signature              string `json:"firma"` // base64 RSA-SHA256 of cadenaOriginal
+}
+
+// registraOrdenResponse: resultado.id is STP's order ID when positive, an
+// error code otherwise.
+type registraOrdenResponse struct {
+	Result struct {
+		ID          int64  `json:"id"`
+		Description string `json:"descripcionError"`
+	} `json:"resultado"`
+}
+
+func newOrdenPago(company string, order ports.PaymentOrder) ordenPago {
+	return ordenPago{
+		CounterpartInstitution: institutionCode(order.BeneficiaryCLABE),
+		Company:                company,
+		TrackingKey:            order.TrackingKey,
+		OperatingInstitution:   stpInstitution,
+		Amount:                 pesos(order),
+		PaymentType:            paymentTypeThirdParty,
+		OriginAccountType:      accountTypeCLABE,
+		OriginName:             order.OriginName,
+		OriginCLABE:            order.OriginCLABE,
+		OriginRFC:              unknownRFC,
+		BeneficiaryAccountType: accountTypeCLABE,
+		BeneficiaryName:        order.BeneficiaryName,
+		BeneficiaryCLABE:       order.BeneficiaryCLABE,
+		BeneficiaryRFC:         unknownRFC,
+		Concept:                order.Concept,
+		Reference:              order.Reference,
+	}
+}
+
+// cadenaOriginal is the string STP signs and verifies: every registraOrden
+// field in the order of STP's specification, pipe separated, between double
+// pipes. Fields we do not send stay empty.
+func (orden ordenPago) cadenaOriginal() string {
+	fields := []string{
+		optionalNumber(orden.CounterpartInstitution),
+		orden.Company,
+		"", // fechaOperacion
+		"", // folioOrigen
+		orden.TrackingKey,
+		optionalNumber(orden.OperatingInstitution),
+		orden.Amount,
+		optionalNumber(orden.PaymentType),
+		optionalNumber(orden.OriginAccountType),
+		orden.OriginName,
+		orden.OriginCLABE,
+		orden.OriginRFC,
+		optionalNumber(orden.BeneficiaryAccountType),
+		orden.BeneficiaryName,
+		orden.BeneficiaryCLABE,
+		orden.BeneficiaryRFC,
+		"", // emailBeneficiario
+		"", // tipoCuentaBeneficiario2
+		"", // nombreBeneficiario2
+		"", // cuentaBeneficiario2
+		"", // rfcCurpBeneficiario2
+		orden.Concept,
+		"", // conceptoPago2
+		"", // claveCatUsuario1
+		"", // claveCatUsuario2
+		"", // clavePago
+		"", // referenciaCobranza
+		optionalNumber(orden.Reference),
+		"", // tipoOperacion
+		"", // topologia
+		"", // usuario
+		"", // medioEntrega
+		"", // prioridad
+		"", // iva
+	}
+	return "||" + strings.Join(fields, "|") + "||"
+}
+
+// sign sets firma with the RSA-SHA256 (PKCS #1 v1.5) signature of the
+// cadena original.
+func (orden *ordenPago) sign(key *rsa.PrivateKey) error {
+	digest := sha256.Sum256([]byte(orden.cadenaOriginal()))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return err
+	}
+	orden.Signature = base64.StdEncoding.EncodeToString(signature)
+	return nil
+}
+
+func (orden ordenPago) verify(key *rsa.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(orden.Signature)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(orden.cadenaOriginal()))
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+}
+
+// institutionCode turns a CLABE's bank code into the Banxico institution
+// code: 40xxx for banks, 90xxx for other participants (codes 600 and up).
+func institutionCode(clabe string) int {
+	if len(clabe) < 3 {
+		return 0
+	}
+	code, _ := strconv.Atoi(clabe[:3])
+	if code >= 600 {
+		return 90_000 + code
+	}
+	return 40_000 + code
+}
+
+// pesos formats the amount as STP wants it: "125.50".
+func pesos(order ports.PaymentOrder) string {
+	amount, _, _ := strings.Cut(order.Amount.String(), " ")
+	return amount
+}
+
+// optionalNumber leaves unset (zero) numeric fields empty in the cadena.
+func optionalNumber(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
@@ -0,0 +1,119 @@
+package stp
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"hexagonal-bank/internal/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// StandIn is a local stand-in for STP's registraOrden endpoint, for tests and
+// local runs against Client. Like STP it verifies each order's signature
+// against its cadena original, refuses tracking keys it has already seen and
+// numbers the orders it registers. Accounts given a holder with SetHolder
+// refuse orders naming someone else.
+type StandIn struct {
+	*httptest.Server
+	publicKey *rsa.PublicKey
+
+	mutex    sync.Mutex
+	orders   map[string]ordenPago // by tracking key
+	holders  map[string]string    // beneficiary name by CLABE
+	nextID   int64
+	failNext int // requests answered 503 without registering
+	loseNext int // requests registered but never answered
+}
+
+// NewStandIn starts a stand-in accepting orders signed with publicKey's
+// private key. Point ClientConfig.BaseURL at its URL and Close it when done.
+func NewStandIn(publicKey *rsa.PublicKey) *StandIn {
+	standIn := &StandIn{publicKey: publicKey, orders: make(map[string]ordenPago), holders: make(map[string]string), nextID: 3_000_000}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /ordenPago/registra", standIn.registraOrden)
+	standIn.Server = httptest.NewServer(mux)
+	return standIn
+}
+
+// FailNext answers the next n orders with 503, as during an outage.
+func (standIn *StandIn) FailNext(n int) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	standIn.failNext = n
+}
+
+// LoseNextAnswers registers the next n orders but drops the connection
+// without answering, as when it breaks after STP took the order.
+func (standIn *StandIn) LoseNextAnswers(n int) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	standIn.loseNext = n
+}
+
+// SetHolder makes orders to clabe fail unless they name holder (case and
+// surrounding spaces aside).
+func (standIn *StandIn) SetHolder(clabe, holder string) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	standIn.holders[clabe] = holder
+}
+
+// Registered reports how many orders were registered.
+func (standIn *StandIn) Registered() int {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	return len(standIn.orders)
+}
+
+func (standIn *StandIn) registraOrden(w http.ResponseWriter, r *http.Request) {
+	var orden ordenPago
+	if err := json.NewDecoder(r.Body).Decode(&orden); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	if standIn.failNext > 0 {
+		standIn.failNext--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var answer registraOrdenResponse
+	switch {
+	case orden.verify(standIn.publicKey) != nil:
+		answer.Result.ID, answer.Result.Description = stpErrInvalidSignature, "Firma invalida"
+	case standIn.orders[orden.TrackingKey].TrackingKey != "":
+		answer.Result.ID, answer.Result.Description = stpErrDuplicateTrackingKey, "Clave de rastreo duplicada"
+	case !validCLABE(orden.BeneficiaryCLABE):
+		answer.Result.ID, answer.Result.Description = stpErrInvalidAccount, "Cuenta beneficiario invalida"
+	case !standIn.holds(orden.BeneficiaryCLABE, orden.BeneficiaryName):
+		answer.Result.ID, answer.Result.Description = stpErrBeneficiaryNameMismatch, "Nombre beneficiario no coincide con la cuenta"
+	default:
+		standIn.nextID++
+		standIn.orders[orden.TrackingKey] = orden
+		answer.Result.ID = standIn.nextID
+	}
+	if standIn.loseNext > 0 && answer.Result.ID > 0 {
+		standIn.loseNext--
+		if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+			_ = conn.Close()
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(answer)
+}
+
+// holds reports whether name matches the holder set for clabe, if any.
+func (standIn *StandIn) holds(clabe, name string) bool {
+	holder, ok := standIn.holders[clabe]
+	return !ok || strings.EqualFold(strings.TrimSpace(holder), strings.TrimSpace(name))
+}
+
+func validCLABE(clabe string) bool {
+	_, err := domain.NewCLABE(clabe)
+	return err == nil
+}
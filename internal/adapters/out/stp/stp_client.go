@@ -0,0 +1,166 @@
+package stp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/platform/backoff"
+	"hexagonal-bank/internal/platform/logging"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// registraOrden error IDs (resultado.id) the client tells apart. Any other
+// non-positive ID is a permanent INVALID_ORDER carrying STP's description.
+const (
+	stpErrDuplicateTrackingKey    = -1
+	stpErrInvalidAccount          = -7
+	stpErrInvalidSignature        = -9
+	stpErrBeneficiaryNameMismatch = -13
+)
+
+const maxResponseBytes = 64 << 10
+
+// Client is the PaymentGateway speaking STP's JSON API. registraOrden only
+// registers an order, so SendTransfer answers PaymentAccepted: the outcome
+// arrives later through the status webhook.
+type Client struct {
+	logger     logging.Logger
+	config     ClientConfig
+	httpClient *http.Client
+	retrier    *backoff.Retrier
+}
+
+func NewClient(logger logging.Logger, config ClientConfig) (*Client, error) {
+	if config.BaseURL == "" || config.Company == "" || config.PrivateKey == nil {
+		return nil, errors.New("stp client: base URL, company and private key are required")
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	retry := config.Retry
+	retry.Classifier = func(err error) bool { return errors.Is(err, ports.ErrPaymentRetryable) && !duplicateOrder(err) }
+	retry.OnRetry = func(attemptIndex int, delay time.Duration, err error) {
+		logger.Warn("STP transient error, retrying", "attempt", attemptIndex, "sleep", delay, "err", err)
+	}
+	return &Client{
+		logger: logger,
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: &http.Transport{TLSClientConfig: config.TLS, Proxy: http.ProxyFromEnvironment},
+		},
+		retrier: backoff.NewRetrier(retry),
+	}, nil
+}
+
+// SendTransfer registers the signed order. Retries reuse the tracking key, so
+// STP never registers an order twice; a duplicate answer to a retry means an
+// earlier attempt got through and only its answer was lost.
+func (client *Client) SendTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	orden := newOrdenPago(client.config.Company, order)
+	if err := orden.sign(client.config.PrivateKey); err != nil {
+		return ports.PaymentResult{}, err
+	}
+	body, err := json.Marshal(orden)
+	if err != nil {
+		return ports.PaymentResult{}, err
+	}
+
+	attempts := 0
+	reference, err := backoff.Retry(ctx, client.retrier, func(ctx context.Context) (string, error) {
+		attempts++
+		reference, err := client.registraOrden(ctx, body)
+		if attempts > 1 && duplicateOrder(err) {
+			return "", nil // registered by an earlier attempt; its ID is unknown
+		}
+		return reference, err
+	})
+	if err != nil {
+		client.logger.Error("STP registraOrden failed", "tracking_key", order.TrackingKey, "to", order.BeneficiaryCLABE, "err", err)
+		return ports.PaymentResult{}, err
+	}
+	return ports.PaymentResult{
+		TrackingKey:   order.TrackingKey,
+		RailReference: reference,
+		Status:        ports.PaymentAccepted,
+		AcceptedAt:    time.Now().UTC(),
+	}, nil
+}
+
+// ReverseTransfer always fails: STP cannot take back a registered order. The
+// beneficiary's bank has to return it ("devolución"), which arrives through
+// the status webhook.
+func (client *Client) ReverseTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	return ports.PaymentResult{}, &ports.PaymentError{Code: ports.RejectInvalidOrder, Reason: "STP orders cannot be reversed, ask for a devolución"}
+}
+
+// registraOrden makes one attempt, returning STP's order ID.
+func (client *Client) registraOrden(ctx context.Context, body []byte) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, client.config.BaseURL+"/ordenPago/registra", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err() // the order may be registered all the same
+		}
+		// Only a failed dial proves the order never reached STP.
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return "", &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: err.Error()}
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", &ports.PaymentError{Code: ports.RejectTimeout, Reason: err.Error()}
+		}
+		return "", &ports.PaymentError{Code: ports.RejectNoAnswer, Reason: err.Error()}
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode >= http.StatusInternalServerError, response.StatusCode == http.StatusTooManyRequests:
+		return "", &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: fmt.Sprintf("STP answered %d", response.StatusCode)}
+	case response.StatusCode != http.StatusOK:
+		return "", &ports.PaymentError{Code: ports.RejectInvalidOrder, Reason: fmt.Sprintf("STP answered %d", response.StatusCode)}
+	}
+	var answer registraOrdenResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxResponseBytes)).Decode(&answer); err != nil {
+		// The order may be registered: retrying is safe, see SendTransfer.
+		return "", &ports.PaymentError{Code: ports.RejectNoAnswer, Reason: "unreadable STP answer: " + err.Error()}
+	}
+	if answer.Result.ID > 0 {
+		return strconv.FormatInt(answer.Result.ID, 10), nil
+	}
+	return "", &ports.PaymentError{Code: rejectionCode(answer.Result.ID), Reason: answer.Result.Description}
+}
+
+// duplicateOrder reports whether STP already has the order's tracking key.
+// Sending it again cannot change that answer, so it is not retried.
+func duplicateOrder(err error) bool {
+	var rejection *ports.PaymentError
+	return errors.As(err, &rejection) && rejection.Code == ports.RejectDuplicateOrder
+}
+
+func rejectionCode(errorID int64) ports.RejectionCode {
+	switch errorID {
+	case stpErrDuplicateTrackingKey:
+		return ports.RejectDuplicateOrder
+	case stpErrInvalidAccount:
+		return ports.RejectInvalidAccount
+	case stpErrBeneficiaryNameMismatch:
+		return ports.RejectBeneficiaryNameMismatch
+	}
+	return ports.RejectInvalidOrder
+}
+
+// Ensure interface compliance
+var _ ports.PaymentGateway = (*Client)(nil)
@@ -0,0 +1,88 @@
+package stp
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/platform/backoff"
+	"os"
+	"time"
+)
+
+// ClientConfig points a Client at STP.
+type ClientConfig struct {
+	BaseURL    string          // e.g. "https://demo.stpmex.com:7024/speiws/rest"
+	Company    string          // "empresa" as registered with STP
+	PrivateKey *rsa.PrivateKey // signs every order's cadena original
+	TLS        *tls.Config     // client certificate and CA bundle; nil uses the system roots
+	Timeout    time.Duration   // per attempt
+
+	// Retry governs retries of transient failures. Its Classifier and OnRetry
+	// are set by the client.
+	Retry backoff.Policy
+}
+
+// DefaultClientConfig waits 10s per attempt and makes up to 3 attempts
+// (full jitter: 500ms base delay, doubling, 5s cap).
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		Timeout: 10 * time.Second,
+		Retry: backoff.Policy{
+			MaxAttempts: 3,
+			Strategy:    backoff.FullJitterBackoff(500*time.Millisecond, 2.0, 5*time.Second),
+		},
+	}
+}
+
+// LoadPrivateKey reads a PEM encoded RSA private key, PKCS #1 or PKCS #8.
+func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA key", path)
+	}
+	return key, nil
+}
+
+// LoadTLSConfig builds the TLS settings for STP: a client certificate when
+// certFile and keyFile are set, and the CA bundle STP's certificate chains
+// to when caFile is set. Empty paths keep Go's defaults.
+func LoadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	if caFile != "" {
+		raw, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return nil, errors.New(caFile + ": no certificates")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
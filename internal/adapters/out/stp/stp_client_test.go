@@ -0,0 +1,132 @@
+package stp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/platform/backoff"
+	"hexagonal-bank/internal/platform/logging"
+	"testing"
+)
+
+func newTestClient(t *testing.T) (*Client, *StandIn) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	standIn := NewStandIn(&key.PublicKey)
+	t.Cleanup(standIn.Close)
+
+	config := DefaultClientConfig()
+	config.BaseURL, config.Company, config.PrivateKey = standIn.URL, "HEXBANK", key
+	config.Retry.Strategy = backoff.ConstantBackoff(0)
+	client, err := NewClient(logging.NewStd(), config)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	return client, standIn
+}
+
+func testOrder(trackingKey string) ports.PaymentOrder {
+	amount, _ := domain.NewMoney(12550, domain.MXN)
+	return ports.PaymentOrder{
+		TrackingKey: trackingKey, OriginCLABE: "032180000118359719", OriginName: "Alice",
+		BeneficiaryCLABE: "012180000118359713", BeneficiaryName: "Carol",
+		Concept: "rent", Reference: 1234567, Amount: amount,
+	}
+}
+
+func TestCadenaOriginal(t *testing.T) {
+	want := "||40012|HEXBANK|||t-1|90646|125.50|1|40|Alice|032180000118359719|ND|40|Carol|012180000118359713|ND" +
+		"||||||rent||||||1234567||||||||"
+	if got := newOrdenPago("HEXBANK", testOrder("t-1")).cadenaOriginal(); got != want {
+		t.Fatalf("cadena original\nwant %s\ngot  %s", want, got)
+	}
+}
+
+func TestClientRegistersSignedOrders(t *testing.T) {
+	client, standIn := newTestClient(t)
+	ctx := context.Background()
+
+	result, err := client.SendTransfer(ctx, testOrder("t-1"))
+	if err != nil || result.Status != ports.PaymentAccepted || result.RailReference != "3000001" {
+		t.Fatalf("want accepted order 3000001 got %+v %v", result, err)
+	}
+	_, err = client.SendTransfer(ctx, testOrder("t-1"))
+	var rejection *ports.PaymentError
+	if !errors.As(err, &rejection) || rejection.Code != ports.RejectDuplicateOrder || !errors.Is(err, ports.ErrPaymentOutcomeUnknown) || standIn.Registered() != 1 {
+		t.Fatalf("a reused tracking key is a DUPLICATE_ORDER with an unknown outcome, got %v", err)
+	}
+
+	order := testOrder("t-2")
+	order.BeneficiaryCLABE = "012180000118359710" // wrong control digit
+	if _, err := client.SendTransfer(ctx, order); !errors.As(err, &rejection) || rejection.Code != ports.RejectInvalidAccount {
+		t.Fatalf("want INVALID_ACCOUNT got %v", err)
+	}
+
+	standIn.SetHolder("012180000118359713", "Carla")
+	_, err = client.SendTransfer(ctx, testOrder("t-4"))
+	if !errors.As(err, &rejection) || rejection.Code != ports.RejectBeneficiaryNameMismatch || !errors.Is(err, ports.ErrPaymentRejected) {
+		t.Fatalf("want BENEFICIARY_NAME_MISMATCH got %v", err)
+	}
+	standIn.SetHolder("012180000118359713", " carol ")
+	if _, err := client.SendTransfer(ctx, testOrder("t-4")); err != nil {
+		t.Fatalf("the holder's name matches regardless of case, got %v", err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	client.config.PrivateKey = other
+	if _, err := client.SendTransfer(ctx, testOrder("t-3")); !errors.As(err, &rejection) || rejection.Code != ports.RejectInvalidOrder {
+		t.Fatalf("a bad signature is an INVALID_ORDER, got %v", err)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	client, standIn := newTestClient(t)
+	ctx := context.Background()
+
+	standIn.FailNext(2)
+	if result, err := client.SendTransfer(ctx, testOrder("t-1")); err != nil || result.RailReference == "" {
+		t.Fatalf("want success on the third attempt got %+v %v", result, err)
+	}
+
+	standIn.LoseNextAnswers(1)
+	if result, err := client.SendTransfer(ctx, testOrder("t-2")); err != nil || result.Status != ports.PaymentAccepted {
+		t.Fatalf("a duplicate after a lost answer means accepted, got %+v %v", result, err)
+	}
+	if standIn.Registered() != 2 {
+		t.Fatalf("want 2 orders registered got %d", standIn.Registered())
+	}
+
+	standIn.FailNext(3)
+	if _, err := client.SendTransfer(ctx, testOrder("t-3")); !errors.Is(err, ports.ErrPaymentRetryable) {
+		t.Fatalf("want a retryable rejection after 3 attempts got %v", err)
+	}
+}
+
+func TestClientReportsUnknownOutcomeWhenLastAnswerIsLost(t *testing.T) {
+	client, standIn := newTestClient(t)
+
+	standIn.FailNext(2)        // the first two attempts are refused...
+	standIn.LoseNextAnswers(1) // ...and the last one is registered, but never answered
+	_, err := client.SendTransfer(context.Background(), testOrder("t-1"))
+	if !errors.Is(err, ports.ErrPaymentOutcomeUnknown) || errors.Is(err, ports.ErrPaymentRejected) {
+		t.Fatalf("want an unknown outcome got %v", err)
+	}
+	if standIn.Registered() != 1 {
+		t.Fatalf("the order is registered, got %d", standIn.Registered())
+	}
+}
+
+func TestClientTreatsUnreachableSTPAsTransient(t *testing.T) {
+	client, standIn := newTestClient(t)
+	standIn.Close() // refuses connections from now on
+	var rejection *ports.PaymentError
+	if _, err := client.SendTransfer(context.Background(), testOrder("t-1")); !errors.As(err, &rejection) || !rejection.Retryable() || rejection.OutcomeUnknown() {
+		t.Fatalf("want a retryable rejection, nothing sent, got %v", err)
+	}
+}
@@ -2,12 +2,18 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"time"
 )
 
 // Readers/Writers are split (ISP) to keep interfaces small.
 type AccountReader interface {
 	ByID(ctx context.Context, id string) (*domain.Account, error)
+	// ByCLABE finds the account registered under an 18-digit CLABE.
+	ByCLABE(ctx context.Context, clabe string) (*domain.Account, error)
 }
 
 type AccountWriter interface {
@@ -15,12 +21,305 @@ type AccountWriter interface {
 	Create(ctx context.Context, account *domain.Account) error
 }
 
+// AccountSort is the field an account search is ordered by.
+type AccountSort string
+
+const (
+	SortByID         AccountSort = "id"
+	SortByHolderName AccountSort = "holder_name"
+	SortByBalance    AccountSort = "balance"
+)
+
+// AccountFilter narrows an account search. Zero values match everything.
+type AccountFilter struct {
+	HolderName  string               // case-insensitive substring
+	CLABEPrefix string               // e.g. "032" for every IXE account
+	Status      domain.AccountStatus // ACTIVE, FROZEN or CLOSED
+	Currency    domain.Currency
+	MinBalance  *int64 // minor units, inclusive
+	MaxBalance  *int64 // minor units, inclusive
+	SortBy      AccountSort
+	Descending  bool
+	Cursor      string // opaque, from a previous AccountPage.NextCursor
+	Limit       int
+}
+
+// AccountPage is one page of a search; NextCursor is empty on the last page.
+type AccountPage struct {
+	Accounts   []*domain.Account
+	NextCursor string
+}
+
+var (
+	// ErrInvalidFilter reports search criteria that make no sense.
+	ErrInvalidFilter = errors.New("invalid search filter")
+	// ErrInvalidCursor is returned for cursors not issued for the same sort order.
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+)
+
+// AccountQuery serves back-office listings with keyset (cursor) pagination,
+// so pages stay stable while accounts are being opened.
+type AccountQuery interface {
+	SearchAccounts(ctx context.Context, filter AccountFilter) (AccountPage, error)
+}
+
+// UnitOfWork runs fn atomically: every write made with the ctx passed to fn
+// is committed together when fn returns nil, and discarded otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TransferReader loads transfers.
+type TransferReader interface {
+	TransferByID(ctx context.Context, id string) (*domain.Transfer, error)
+	// TransferBySPEIKey finds a SPEI transfer by direction, sending bank,
+	// clave de rastreo and operation date (see domain.SPEIKey).
+	TransferBySPEIKey(ctx context.Context, key domain.SPEIKey) (*domain.Transfer, error)
+	// PendingTransfers returns up to limit PENDING transfers, oldest first.
+	PendingTransfers(ctx context.Context, limit int) ([]*domain.Transfer, error)
+}
+
+// TransferWriter persists transfers.
+type TransferWriter interface {
+	CreateTransfer(ctx context.Context, transfer *domain.Transfer) error
+	SaveTransfer(ctx context.Context, transfer *domain.Transfer) error
+}
+
+// LedgerReader exposes balances derived from posted journal entries.
+type LedgerReader interface {
+	BalanceOf(ctx context.Context, accountID string) (int64, error)
+}
+
+// LedgerWriter appends balanced journal entries (append-only).
+type LedgerWriter interface {
+	Post(ctx context.Context, entry ledger.Entry) error
+}
+
+// TransactionReader serves an account's statement (newest first), paginated.
+// It returns the requested page and the total number of lines.
+type TransactionReader interface {
+	Transactions(ctx context.Context, accountID string, offset, limit int) ([]ledger.Line, int, error)
+}
+
+// PaymentOrder is an outbound SPEI payment as the rail needs it.
+type PaymentOrder struct {
+	TrackingKey      string // "clave de rastreo", see domain.Transfer.TrackingKey
+	OriginCLABE      string
+	OriginName       string
+	BeneficiaryCLABE string
+	BeneficiaryName  string
+	Concept          string
+	Reference        int
+	Amount           domain.Money
+}
+
+// PaymentStatus is where the rail stands on an order it took.
+type PaymentStatus string
+
+const (
+	// PaymentSettled: the rail settled the order before answering.
+	PaymentSettled PaymentStatus = "SETTLED"
+	// PaymentAccepted: the rail queued the order; the outcome arrives later
+	// through a status callback.
+	PaymentAccepted PaymentStatus = "ACCEPTED"
+)
+
+// PaymentResult is the rail's answer to an order it took.
+type PaymentResult struct {
+	TrackingKey   string
+	RailReference string // the rail's own order ID
+	Status        PaymentStatus
+	AcceptedAt    time.Time
+	SettledAt     time.Time // zero unless Status is PaymentSettled
+}
+
+// RejectionCode says why the rail did not take an order.
+type RejectionCode string
+
+const (
+	// Retryable: the order may go through if sent again later.
+	RejectRailUnavailable RejectionCode = "RAIL_UNAVAILABLE"
+	// Retryable, but the outcome is unknown: the order reached the rail and
+	// no usable answer came back, so the rail may have taken it.
+	RejectTimeout  RejectionCode = "TIMEOUT"
+	RejectNoAnswer RejectionCode = "NO_ANSWER"
+	// Outcome unknown too: the rail already has the tracking key, so an
+	// earlier send of the same order may have gone through.
+	RejectDuplicateOrder RejectionCode = "DUPLICATE_ORDER"
+	// Permanent: sending the same order again fails the same way.
+	RejectInvalidAccount          RejectionCode = "INVALID_ACCOUNT"
+	RejectBeneficiaryNameMismatch RejectionCode = "BENEFICIARY_NAME_MISMATCH"
+	RejectInvalidOrder            RejectionCode = "INVALID_ORDER"
+)
+
+var (
+	// ErrPaymentRetryable matches rejections worth retrying later.
+	ErrPaymentRetryable = errors.New("payment rail temporarily unavailable")
+	// ErrPaymentRejected matches permanent rejections.
+	ErrPaymentRejected = errors.New("payment rejected by the rail")
+	// ErrPaymentOutcomeUnknown matches rejections after which the rail may
+	// still have taken the order (see PaymentError.OutcomeUnknown).
+	ErrPaymentOutcomeUnknown = errors.New("payment outcome unknown")
+)
+
+// PaymentError is a PaymentGateway rejection. It matches ErrPaymentRetryable
+// or ErrPaymentRejected with errors.Is, depending on its code, and also
+// ErrPaymentOutcomeUnknown when the order may have gone through.
+type PaymentError struct {
+	Code   RejectionCode
+	Reason string // the rail's own description
+}
+
+func (e *PaymentError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.kind(), e.Code, e.Reason)
+}
+
+func (e *PaymentError) Is(target error) bool {
+	return target == e.kind() || target == ErrPaymentOutcomeUnknown && e.OutcomeUnknown()
+}
+
+// Retryable reports whether the same order may succeed later.
+func (e *PaymentError) Retryable() bool {
+	return e.Code == RejectRailUnavailable || e.OutcomeUnknown()
+}
+
+// OutcomeUnknown reports whether the rail may have taken the order anyway.
+// Sending it again with the same tracking key is safe, but the order must not
+// be treated as failed until the rail says so.
+func (e *PaymentError) OutcomeUnknown() bool {
+	return e.Code == RejectTimeout || e.Code == RejectNoAnswer || e.Code == RejectDuplicateOrder
+}
+
+func (e *PaymentError) kind() error {
+	if e.Retryable() {
+		return ErrPaymentRetryable
+	}
+	return ErrPaymentRejected
+}
+
 // PaymentGateway abstracts an external payment rail (here: STP).
+// Rejections are returned as *PaymentError.
 type PaymentGateway interface {
-	SendTransfer(ctx context.Context, fromID, toID string, cents int64) (string, error)
+	SendTransfer(ctx context.Context, order PaymentOrder) (PaymentResult, error)
+	// ReverseTransfer undoes an order previously sent (same tracking key).
+	ReverseTransfer(ctx context.Context, order PaymentOrder) (PaymentResult, error)
+}
+
+// ErrPaymentUnavailable matches *GatewayUnavailableError.
+var ErrPaymentUnavailable = errors.New("payment gateway unavailable")
+
+// GatewayUnavailableError is returned without calling the rail while it is
+// known to be down (circuit open). Nothing was sent: the same order can be
+// tried again after RetryAfter.
+type GatewayUnavailableError struct {
+	RetryAfter time.Duration
+}
+
+func (e *GatewayUnavailableError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrPaymentUnavailable, e.RetryAfter)
+}
+
+func (e *GatewayUnavailableError) Is(target error) bool { return target == ErrPaymentUnavailable }
+
+// CircuitState is the state of the circuit breaker guarding the rail.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "CLOSED"    // calls go through
+	CircuitOpen     CircuitState = "OPEN"      // calls fail fast until the cool-down ends
+	CircuitHalfOpen CircuitState = "HALF_OPEN" // a trial call decides
+)
+
+// GatewayHealth is how the PaymentGateway sees the rail right now.
+type GatewayHealth struct {
+	State               CircuitState
+	ConsecutiveFailures int
+	RetryAfter          time.Duration // until the next trial call, while OPEN
+}
+
+// GatewayMonitor reports the PaymentGateway's health, so callers can fail fast
+// instead of taking work the gateway would refuse.
+type GatewayMonitor interface {
+	Health() GatewayHealth
+}
+
+// FXRateProvider quotes exchange rates. Quotes are remembered by ID so a
+// client can lock a rate first and transfer with it later (until it expires).
+type FXRateProvider interface {
+	Quote(ctx context.Context, from, to domain.Currency) (domain.FXQuote, error)
+	QuoteByID(ctx context.Context, quoteID string) (domain.FXQuote, error)
+}
+
+// Inconsistency describes a mismatch between the payment rail and our books
+// that a human has to review.
+type Inconsistency struct {
+	TransferID string
+	Reason     string // what went wrong in our books
+	Resolution string // what was done automatically (e.g. reversal outcome)
+	DetectedAt time.Time
+}
+
+// InconsistencyRecorder keeps inconsistencies for manual review.
+type InconsistencyRecorder interface {
+	RecordInconsistency(ctx context.Context, inconsistency Inconsistency) error
 }
 
 // EventPublisher is a simplified event bus.
 type EventPublisher interface {
 	Publish(ctx context.Context, topic string, payload any) error
 }
+
+// Outbox records integration events in the same unit of work as the state
+// change that produced them; a relay publishes them afterwards.
+type Outbox interface {
+	Enqueue(ctx context.Context, topic string, payload any) error
+}
+
+// OutboxMessage is an event waiting in the outbox.
+type OutboxMessage struct {
+	ID            string
+	Topic         string
+	Payload       any
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
+
+// OutboxRelayStore is the relay's view of the outbox.
+type OutboxRelayStore interface {
+	// Due returns up to limit unpublished messages whose NextAttemptAt <= now, oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error)
+	MarkPublished(ctx context.Context, messageID string) error
+	ScheduleRetry(ctx context.Context, messageID string, nextAttemptAt time.Time, lastError string) error
+}
+
+// IdempotencyRecord is what an IdempotencyStore keeps per Idempotency-Key.
+// A record without StatusCode is still in progress.
+type IdempotencyRecord struct {
+	Fingerprint string // hash of the original request (method, path, body)
+	StatusCode  int
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// ErrIdempotencyKeyInUse is returned by Reserve when another request
+// holds the key and has not completed yet.
+var ErrIdempotencyKeyInUse = errors.New("idempotency key in use by a concurrent request")
+
+// ErrIdempotencyKeyMismatch is returned by Reserve when the key was first
+// used with a different request, whether or not that one has completed.
+var ErrIdempotencyKeyMismatch = errors.New("idempotency key already used with a different request")
+
+// IdempotencyStore remembers the first response given for a client key so
+// retries can be replayed instead of executed twice.
+type IdempotencyStore interface {
+	// Reserve claims key for a new request. If key was already completed for
+	// the same fingerprint it returns the stored record (replay) and does not
+	// reserve.
+	Reserve(ctx context.Context, key, fingerprint string) (*IdempotencyRecord, error)
+	// Complete stores the final response of a reserved key.
+	Complete(ctx context.Context, key string, record IdempotencyRecord) error
+	// Release frees a reserved key so the client can retry (e.g. after a 5xx).
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+)
+
+type AccountStatusOutput struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	Balance      string `json:"balance"`
+	BalanceCents int64  `json:"balance_cents"`
+	Currency     string `json:"currency"`
+}
+
+// accountStatusChanger applies a lifecycle transition and saves the account,
+// replaying the read-modify-write when a concurrent writer got there first.
+type accountStatusChanger struct {
+	accountReader ports.AccountReader
+	accountWriter ports.AccountWriter
+}
+
+func (changer accountStatusChanger) change(ctx context.Context, accountID string, transition func(*domain.Account) error) (AccountStatusOutput, error) {
+	var output AccountStatusOutput
+	err := retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		account, err := changer.accountReader.ByID(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if err := transition(account); err != nil {
+			return err
+		}
+		if err := changer.accountWriter.Save(ctx, account); err != nil {
+			return err
+		}
+		output = AccountStatusOutput{
+			ID:           account.ID,
+			Status:       string(account.Status()),
+			Balance:      account.Balance().String(),
+			BalanceCents: account.Balance().Amount(),
+			Currency:     string(account.Currency()),
+		}
+		return nil
+	})
+	return output, err
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+)
+
+func TestAccountStatusUseCases(t *testing.T) {
+	ctx := context.Background()
+	accounts, journal := memory.NewAccountRepo(), memory.NewLedgerRepo()
+	account, err := NewOpenAccountUseCase(accounts).Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	deposit := NewDepositMoneyUseCase(accounts, accounts, journal, journal, accounts)
+	withdraw := NewWithdrawMoneyUseCase(accounts, accounts, journal, journal, accounts)
+	freeze := NewFreezeAccountUseCase(accounts, accounts)
+	unfreeze := NewUnfreezeAccountUseCase(accounts, accounts)
+	closeAccount := NewCloseAccountUseCase(accounts, accounts)
+
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(500)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if output, err := freeze.Execute(ctx, account.ID); err != nil || output.Status != "FROZEN" {
+		t.Fatalf("freeze: %+v %v", output, err)
+	}
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(500)}); !errors.Is(err, domain.ErrAccountFrozen) {
+		t.Fatalf("deposit to frozen account: want ErrAccountFrozen got %v", err)
+	}
+	if _, err := unfreeze.Execute(ctx, account.ID); err != nil {
+		t.Fatalf("unfreeze: %v", err)
+	}
+	if _, err := closeAccount.Execute(ctx, account.ID); !errors.Is(err, domain.ErrAccountNotEmpty) {
+		t.Fatalf("close with balance: want ErrAccountNotEmpty got %v", err)
+	}
+	if _, err := withdraw.Execute(ctx, WithdrawInput{AccountID: account.ID, Amount: mxn(500), Channel: "BRANCH"}); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if output, err := closeAccount.Execute(ctx, account.ID); err != nil || output.Status != "CLOSED" {
+		t.Fatalf("close: %+v %v", output, err)
+	}
+	if _, err := freeze.Execute(ctx, account.ID); !errors.Is(err, domain.ErrInvalidAccountTransition) {
+		t.Fatalf("freeze closed account: want ErrInvalidAccountTransition got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+)
+
+// CloseAccountUseCase permanently closes an empty account.
+type CloseAccountUseCase struct {
+	changer accountStatusChanger
+}
+
+func NewCloseAccountUseCase(accountReader ports.AccountReader, accountWriter ports.AccountWriter) *CloseAccountUseCase {
+	return &CloseAccountUseCase{changer: accountStatusChanger{accountReader: accountReader, accountWriter: accountWriter}}
+}
+
+func (useCase *CloseAccountUseCase) Execute(ctx context.Context, accountID string) (AccountStatusOutput, error) {
+	return useCase.changer.change(ctx, accountID, (*domain.Account).Close)
+}
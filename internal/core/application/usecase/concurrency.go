@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+)
+
+// defaultConflictAttempts bounds how often a read-modify-write is replayed
+// after an optimistic concurrency conflict.
+const defaultConflictAttempts = 3
+
+// retryOnConflict runs attempt up to attempts times while it fails with
+// domain.ErrVersionConflict. attempt must re-read every aggregate it writes,
+// otherwise it would keep saving the same stale version.
+func retryOnConflict(ctx context.Context, attempts int, attempt func() error) error {
+	var err error
+	for try := 0; try < max(attempts, 1); try++ {
+		if err = attempt(); !errors.Is(err, domain.ErrVersionConflict) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+	return err
+}
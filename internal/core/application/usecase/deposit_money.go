@@ -3,39 +3,101 @@ package usecase
 import (
 	"context"
 	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/shared/id"
+	"time"
 )
 
 type DepositInput struct {
 	AccountID string
-	Cents     int64
+	Amount    domain.Money
 }
 
 type DepositOutput struct {
-	ID      string `json:"id"`
-	Balance int64  `json:"balance_cents"`
+	ID           string `json:"id"`
+	Balance      string `json:"balance"`
+	BalanceCents int64  `json:"balance_cents"`
+	Currency     string `json:"currency"`
 }
 
 type DepositMoneyUseCase struct {
 	accountReader ports.AccountReader
 	accountWriter ports.AccountWriter
+	ledgerReader  ports.LedgerReader
+	ledgerWriter  ports.LedgerWriter
+	unitOfWork    ports.UnitOfWork
 }
 
-func NewDepositMoneyUseCase(accountReader ports.AccountReader, accountWriter ports.AccountWriter) *DepositMoneyUseCase {
-	return &DepositMoneyUseCase{accountReader: accountReader, accountWriter: accountWriter}
+func NewDepositMoneyUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+) *DepositMoneyUseCase {
+	return &DepositMoneyUseCase{
+		accountReader: accountReader,
+		accountWriter: accountWriter,
+		ledgerReader:  ledgerReader,
+		ledgerWriter:  ledgerWriter,
+		unitOfWork:    unitOfWork,
+	}
 }
 
+// Execute credits the account, replaying the whole read-modify-write when a
+// concurrent writer got there first.
 func (useCase *DepositMoneyUseCase) Execute(ctx context.Context, input DepositInput) (DepositOutput, error) {
-	account, err := useCase.accountReader.ByID(ctx, input.AccountID)
-	if err != nil {
-		return DepositOutput{}, err
-	}
-	if err := account.Credit(input.Cents); err != nil {
-		return DepositOutput{}, err
-	}
-	if err := useCase.accountWriter.Save(ctx, account); err != nil {
-		return DepositOutput{}, err
-	}
-	return DepositOutput{ID: account.ID, Balance: account.Balance()}, nil
+	var output DepositOutput
+	err := retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		var err error
+		output, err = useCase.deposit(ctx, input)
+		return err
+	})
+	return output, err
+}
+
+// deposit reads, credits and writes the account inside one unit of work, so
+// the ledger it reconciles against cannot move underneath it.
+func (useCase *DepositMoneyUseCase) deposit(ctx context.Context, input DepositInput) (DepositOutput, error) {
+	var output DepositOutput
+	err := useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		account, err := useCase.accountReader.ByID(ctx, input.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := account.Credit(input.Amount); err != nil {
+			return err
+		}
+
+		// Cash enters the bank and is owed to the account holder.
+		entry, err := ledger.NewEntry(id.New(), ledger.KindDeposit, time.Now().UTC(),
+			ledger.DebitLeg(ledger.CashAccount, input.Amount),
+			ledger.CreditLeg(account.ID, input.Amount),
+		)
+		if err != nil {
+			return err
+		}
+		if err := reconcileEntry(ctx, useCase.ledgerReader, entry, account); err != nil {
+			return err
+		}
+
+		// Account and journal are persisted together or not at all
+		if err := useCase.accountWriter.Save(ctx, account); err != nil {
+			return err
+		}
+		if err := useCase.ledgerWriter.Post(ctx, entry); err != nil {
+			return err
+		}
+		output = DepositOutput{
+			ID:           account.ID,
+			Balance:      account.Balance().String(),
+			BalanceCents: account.Balance().Amount(),
+			Currency:     string(account.Currency()),
+		}
+		return nil
+	})
+	return output, err
 }
 
 // ReaderPort exposes the reader (used by HTTP adapter for GET /accounts/:id)
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowLedgerReader widens the window between reading the ledger and writing
+// the account, where a concurrent writer could slip in.
+type slowLedgerReader struct{ *memory.LedgerRepository }
+
+func (reader slowLedgerReader) BalanceOf(ctx context.Context, accountID string) (int64, error) {
+	time.Sleep(time.Millisecond)
+	return reader.LedgerRepository.BalanceOf(ctx, accountID)
+}
+
+func TestConcurrentDepositsAllSucceed(t *testing.T) {
+	ctx := context.Background()
+	accounts, journal := memory.NewAccountRepo(), memory.NewLedgerRepo()
+	account, err := NewOpenAccountUseCase(accounts).Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	deposit := NewDepositMoneyUseCase(accounts, accounts, slowLedgerReader{journal}, journal, accounts)
+
+	const depositors = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, depositors)
+	for range depositors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(100)})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent deposit: %v", err)
+		}
+	}
+	if stored, _ := accounts.ByID(ctx, account.ID); stored.Balance().Amount() != depositors*100 {
+		t.Fatalf("balance want=%d got=%s", depositors*100, stored.Balance())
+	}
+	if balance, _ := journal.BalanceOf(ctx, account.ID); balance != depositors*100 {
+		t.Fatalf("ledger balance want=%d got=%d", depositors*100, balance)
+	}
+}
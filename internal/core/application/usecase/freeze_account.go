@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+)
+
+// FreezeAccountUseCase blocks all money movement on a suspicious account.
+type FreezeAccountUseCase struct {
+	changer accountStatusChanger
+}
+
+func NewFreezeAccountUseCase(accountReader ports.AccountReader, accountWriter ports.AccountWriter) *FreezeAccountUseCase {
+	return &FreezeAccountUseCase{changer: accountStatusChanger{accountReader: accountReader, accountWriter: accountWriter}}
+}
+
+func (useCase *FreezeAccountUseCase) Execute(ctx context.Context, accountID string) (AccountStatusOutput, error) {
+	return useCase.changer.change(ctx, accountID, (*domain.Account).Freeze)
+}
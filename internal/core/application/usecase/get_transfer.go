@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+)
+
+// GetTransferUseCase returns the current state of a transfer.
+type GetTransferUseCase struct {
+	transferReader ports.TransferReader
+}
+
+func NewGetTransferUseCase(transferReader ports.TransferReader) *GetTransferUseCase {
+	return &GetTransferUseCase{transferReader: transferReader}
+}
+
+func (useCase *GetTransferUseCase) Execute(ctx context.Context, transferID string) (TransferOutput, error) {
+	transfer, err := useCase.transferReader.TransferByID(ctx, transferID)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	return newTransferOutput(transfer), nil
+}
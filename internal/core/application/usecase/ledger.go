@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+)
+
+// reconcileEntry checks that applying entry on top of the posted ledger
+// yields exactly the balances held by the (already mutated) accounts.
+// It runs before anything is persisted so a mismatch aborts the operation.
+func reconcileEntry(ctx context.Context, ledgerReader ports.LedgerReader, entry ledger.Entry, accounts ...*domain.Account) error {
+	for _, account := range accounts {
+		posted, err := ledgerReader.BalanceOf(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if err := ledger.Reconcile(account.ID, account.Balance().Amount(), posted+entry.AmountFor(account.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
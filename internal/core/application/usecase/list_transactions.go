@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"time"
+)
+
+const (
+	defaultTransactionsPageSize = 20
+	maxTransactionsPageSize     = 100
+)
+
+type ListTransactionsInput struct {
+	AccountID string
+	Offset    int
+	Limit     int
+}
+
+type TransactionItem struct {
+	EntryID        string    `json:"entry_id"`
+	Kind           string    `json:"kind"`
+	PostedAt       time.Time `json:"posted_at"`
+	Counterparty   string    `json:"counterparty"`
+	Amount         int64     `json:"amount_cents"`
+	Currency       string    `json:"currency"`
+	RunningBalance int64     `json:"running_balance_cents"`
+}
+
+type ListTransactionsOutput struct {
+	AccountID  string            `json:"account_id"`
+	Items      []TransactionItem `json:"items"`
+	Total      int               `json:"total"`
+	Offset     int               `json:"offset"`
+	Limit      int               `json:"limit"`
+	NextOffset *int              `json:"next_offset,omitempty"`
+}
+
+// ListTransactionsUseCase returns an account's statement, newest first.
+type ListTransactionsUseCase struct {
+	accountReader     ports.AccountReader
+	transactionReader ports.TransactionReader
+}
+
+func NewListTransactionsUseCase(accountReader ports.AccountReader, transactionReader ports.TransactionReader) *ListTransactionsUseCase {
+	return &ListTransactionsUseCase{accountReader: accountReader, transactionReader: transactionReader}
+}
+
+func (useCase *ListTransactionsUseCase) Execute(ctx context.Context, input ListTransactionsInput) (ListTransactionsOutput, error) {
+	if _, err := useCase.accountReader.ByID(ctx, input.AccountID); err != nil {
+		return ListTransactionsOutput{}, err
+	}
+
+	offset := max(input.Offset, 0)
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultTransactionsPageSize
+	}
+	limit = min(limit, maxTransactionsPageSize)
+
+	lines, total, err := useCase.transactionReader.Transactions(ctx, input.AccountID, offset, limit)
+	if err != nil {
+		return ListTransactionsOutput{}, err
+	}
+	items := make([]TransactionItem, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, TransactionItem{
+			EntryID:        line.EntryID,
+			Kind:           line.Kind,
+			PostedAt:       line.PostedAt,
+			Counterparty:   line.Counterparty,
+			Amount:         line.Amount,
+			Currency:       string(line.Currency),
+			RunningBalance: line.RunningBalance,
+		})
+	}
+	output := ListTransactionsOutput{
+		AccountID: input.AccountID,
+		Items:     items,
+		Total:     total,
+		Offset:    offset,
+		Limit:     limit,
+	}
+	if next := offset + len(items); next < total {
+		output.NextOffset = &next
+	}
+	return output, nil
+}
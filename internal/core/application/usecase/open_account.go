@@ -11,14 +11,21 @@ import (
 type OpenAccountInput struct {
 	HolderName string
 	CLABE      string
+	Currency   string // ISO 4217; defaults to MXN
 }
 
 // OpenAccountOutput is the response DTO.
 type OpenAccountOutput struct {
-	ID         string `json:"id"`
-	HolderName string `json:"holder_name"`
-	CLABE      string `json:"clabe"`
-	Balance    int64  `json:"balance_cents"`
+	ID           string `json:"id"`
+	HolderName   string `json:"holder_name"`
+	CLABE        string `json:"clabe"`
+	BankCode     string `json:"bank_code"`
+	BankName     string `json:"bank_name"`
+	Plaza        string `json:"plaza"`
+	Status       string `json:"status"`
+	Currency     string `json:"currency"`
+	Balance      string `json:"balance"`
+	BalanceCents int64  `json:"balance_cents"`
 }
 
 // OpenAccountUseCase orchestrates account creation.
@@ -35,8 +42,14 @@ func (useCase *OpenAccountUseCase) Execute(ctx context.Context, input OpenAccoun
 	if err != nil {
 		return OpenAccountOutput{}, err
 	}
+	currency := domain.MXN
+	if input.Currency != "" {
+		if currency, err = domain.ParseCurrency(input.Currency); err != nil {
+			return OpenAccountOutput{}, err
+		}
+	}
 	newID := id.New()
-	account, err := domain.NewAccount(newID, input.HolderName, clabe)
+	account, err := domain.NewAccount(newID, input.HolderName, clabe, currency)
 	if err != nil {
 		return OpenAccountOutput{}, err
 	}
@@ -44,9 +57,15 @@ func (useCase *OpenAccountUseCase) Execute(ctx context.Context, input OpenAccoun
 		return OpenAccountOutput{}, err
 	}
 	return OpenAccountOutput{
-		ID:         account.ID,
-		HolderName: account.HolderName(),
-		CLABE:      account.CLABE(),
-		Balance:    account.Balance(),
+		ID:           account.ID,
+		HolderName:   account.HolderName(),
+		CLABE:        account.CLABE(),
+		BankCode:     clabe.BankCode(),
+		BankName:     clabe.BankName(),
+		Plaza:        clabe.Plaza(),
+		Status:       string(account.Status()),
+		Currency:     string(account.Currency()),
+		Balance:      account.Balance().String(),
+		BalanceCents: account.Balance().Amount(),
 	}, nil
 }
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/shared/id"
+	"time"
+)
+
+// outboundSettlement books the outcome of an outbound SPEI transfer. Its
+// methods run inside the caller's unit of work.
+type outboundSettlement struct {
+	accountReader  ports.AccountReader
+	accountWriter  ports.AccountWriter
+	transferWriter ports.TransferWriter
+	ledgerReader   ports.LedgerReader
+	ledgerWriter   ports.LedgerWriter
+	outbox         ports.Outbox
+}
+
+// settle moves the money in our books: the captured hold, the journal entry
+// (owed to the other bank through SPEI clearing), the transfer status and the
+// integration event are written atomically. settledAt is when the rail
+// settled the order.
+func (settlement outboundSettlement) settle(ctx context.Context, transfer *domain.Transfer, settledAt time.Time) error {
+	fromAccount, err := settlement.accountReader.ByID(ctx, transfer.FromID())
+	if err != nil {
+		return err
+	}
+
+	// Domain rules first: the reserved funds leave the source account
+	if err := transfer.MarkSettled(settledAt); err != nil {
+		return err
+	}
+	if _, err := fromAccount.CaptureHold(transfer.ID); err != nil {
+		return err
+	}
+	entry, err := ledger.NewEntry(id.New(), ledger.KindTransfer, time.Now().UTC(),
+		ledger.DebitLeg(fromAccount.ID, transfer.Amount()),
+		ledger.CreditLeg(ledger.SPEIClearingAccount, transfer.Amount()),
+	)
+	if err != nil {
+		return err
+	}
+	if err := reconcileEntry(ctx, settlement.ledgerReader, entry, fromAccount); err != nil {
+		return err
+	}
+
+	if err := settlement.accountWriter.Save(ctx, fromAccount); err != nil {
+		return err
+	}
+	if err := settlement.ledgerWriter.Post(ctx, entry); err != nil {
+		return err
+	}
+	if err := settlement.transferWriter.SaveTransfer(ctx, transfer); err != nil {
+		return err
+	}
+	// Relayed to the EventPublisher after commit (see worker.OutboxRelay)
+	return settlement.outbox.Enqueue(ctx, "transfer.completed", map[string]any{
+		"transfer_id": transfer.ID, "kind": transfer.Kind(), "from_id": transfer.FromID(),
+		"beneficiary_clabe": transfer.Beneficiary().CLABE().String(), "amount": transfer.Amount().String(),
+	})
+}
+
+// returnFunds gives the money back to the source account when the
+// beneficiary's bank returns the transfer: the hold is released if the
+// transfer had not settled yet, otherwise the amount is refunded from SPEI
+// clearing, even if the account was frozen since. An account closed since
+// cannot take it, so the money goes to the suspense account instead and
+// suspended is true: the caller must have someone pay it out.
+func (settlement outboundSettlement) returnFunds(ctx context.Context, transfer *domain.Transfer, reason string) (suspended bool, err error) {
+	fromAccount, err := settlement.accountReader.ByID(ctx, transfer.FromID())
+	if err != nil {
+		return false, err
+	}
+	wasSettled := transfer.Status() == domain.TransferSettled
+	if err := transfer.MarkReturned(reason, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	if !wasSettled {
+		if err := fromAccount.ReleaseHold(transfer.ID); err != nil {
+			return false, err
+		}
+	} else {
+		creditTo := fromAccount.ID
+		suspended = fromAccount.Status() == domain.AccountClosed
+		if suspended {
+			creditTo = ledger.SuspenseAccount
+		} else if err := fromAccount.Refund(transfer.Amount()); err != nil {
+			return false, err
+		}
+		entry, err := ledger.NewEntry(id.New(), ledger.KindTransfer, time.Now().UTC(),
+			ledger.DebitLeg(ledger.SPEIClearingAccount, transfer.Amount()),
+			ledger.CreditLeg(creditTo, transfer.Amount()),
+		)
+		if err != nil {
+			return false, err
+		}
+		if !suspended {
+			if err := reconcileEntry(ctx, settlement.ledgerReader, entry, fromAccount); err != nil {
+				return false, err
+			}
+		}
+		if err := settlement.ledgerWriter.Post(ctx, entry); err != nil {
+			return false, err
+		}
+	}
+
+	if !suspended {
+		if err := settlement.accountWriter.Save(ctx, fromAccount); err != nil {
+			return false, err
+		}
+	}
+	if err := settlement.transferWriter.SaveTransfer(ctx, transfer); err != nil {
+		return false, err
+	}
+	// Relayed to the EventPublisher after commit (see worker.OutboxRelay)
+	return suspended, settlement.outbox.Enqueue(ctx, "transfer.returned", map[string]any{
+		"transfer_id": transfer.ID, "from_id": transfer.FromID(), "tracking_key": transfer.TrackingKey(),
+		"amount": transfer.Amount().String(), "reason": reason, "suspended": suspended,
+	})
+}
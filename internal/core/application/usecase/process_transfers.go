@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"time"
+)
+
+// Default limits on how long an outbound transfer keeps its funds held while
+// the rail keeps asking to retry later (see WithRetryLimits).
+const (
+	DefaultMaxSendAttempts = 10
+	DefaultMaxPendingAge   = 24 * time.Hour
+)
+
+type ProcessTransfersOutput struct {
+	Sent     int // left SENT, outcome arrives by status callback
+	Settled  int
+	Failed   int
+	Reversed int
+	Deferred int // left PENDING: the gateway's circuit is open or the rail asked to retry later
+}
+
+// ProcessTransfersUseCase drives PENDING outbound transfers through the PaymentGateway:
+// PENDING -> SENT -> SETTLED (the hold is captured), or FAILED when the gateway
+// rejects it for good (the hold is released).
+// A gateway that settles asynchronously answers PaymentAccepted: the transfer
+// stays SENT, with its hold, until UpdateTransferStatusUseCase gets the outcome.
+// The rail's reference and timestamps are kept on the transfer.
+// Retryable rejections (see ports.PaymentError) put the transfer back to
+// PENDING, with its hold, for a later batch; permanent ones fail it. Both are
+// recorded on the transfer. While the gateway's circuit is open the remaining
+// transfers stay PENDING; a transfer the gateway refused to send
+// (ports.ErrPaymentUnavailable) goes back to PENDING too. A transfer is not
+// retried forever: once rejected maxAttempts times, or still PENDING maxAge
+// after it was created, it fails and its hold is released.
+//
+// When the rail may have taken the order but did not say so (no answer, a
+// cancelled call, a duplicate tracking key), the transfer stays SENT with its
+// hold until the status callback tells, and the case is recorded for review.
+//
+// When STP accepted the transfer but our books cannot be updated, a saga-style
+// compensation reverses it on STP (SENT -> REVERSED) and records the
+// inconsistency for manual review.
+type ProcessTransfersUseCase struct {
+	accountReader   ports.AccountReader
+	accountWriter   ports.AccountWriter
+	transferReader  ports.TransferReader
+	transferWriter  ports.TransferWriter
+	ledgerReader    ports.LedgerReader
+	ledgerWriter    ports.LedgerWriter
+	unitOfWork      ports.UnitOfWork
+	paymentGateway  ports.PaymentGateway
+	gatewayMonitor  ports.GatewayMonitor
+	inconsistencies ports.InconsistencyRecorder
+	settlement      outboundSettlement
+	maxAttempts     int
+	maxAge          time.Duration
+	now             func() time.Time
+}
+
+func NewProcessTransfersUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	transferReader ports.TransferReader,
+	transferWriter ports.TransferWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+	paymentGateway ports.PaymentGateway,
+	gatewayMonitor ports.GatewayMonitor,
+	outbox ports.Outbox,
+	inconsistencies ports.InconsistencyRecorder,
+) *ProcessTransfersUseCase {
+	return &ProcessTransfersUseCase{
+		accountReader:   accountReader,
+		accountWriter:   accountWriter,
+		transferReader:  transferReader,
+		transferWriter:  transferWriter,
+		ledgerReader:    ledgerReader,
+		ledgerWriter:    ledgerWriter,
+		unitOfWork:      unitOfWork,
+		paymentGateway:  paymentGateway,
+		gatewayMonitor:  gatewayMonitor,
+		inconsistencies: inconsistencies,
+		settlement: outboundSettlement{
+			accountReader:  accountReader,
+			accountWriter:  accountWriter,
+			transferWriter: transferWriter,
+			ledgerReader:   ledgerReader,
+			ledgerWriter:   ledgerWriter,
+			outbox:         outbox,
+		},
+		maxAttempts: DefaultMaxSendAttempts,
+		maxAge:      DefaultMaxPendingAge,
+		now:         time.Now,
+	}
+}
+
+// WithRetryLimits replaces the default limits on retrying a transfer the rail
+// keeps rejecting as retryable, or cannot be sent to.
+func (useCase *ProcessTransfersUseCase) WithRetryLimits(maxAttempts int, maxAge time.Duration) *ProcessTransfersUseCase {
+	useCase.maxAttempts, useCase.maxAge = maxAttempts, maxAge
+	return useCase
+}
+
+// WithClock replaces time.Now for the age limit, e.g. to test it.
+func (useCase *ProcessTransfersUseCase) WithClock(now func() time.Time) *ProcessTransfersUseCase {
+	useCase.now = now
+	return useCase
+}
+
+// Execute processes up to batchSize pending transfers, oldest first.
+// A failing transfer does not stop the batch; only infrastructure errors do.
+func (useCase *ProcessTransfersUseCase) Execute(ctx context.Context, batchSize int) (ProcessTransfersOutput, error) {
+	var output ProcessTransfersOutput
+	pending, err := useCase.transferReader.PendingTransfers(ctx, batchSize)
+	if err != nil {
+		return output, err
+	}
+	for _, transfer := range pending {
+		if err := ctx.Err(); err != nil {
+			return output, err // do not leave transfers half-sent
+		}
+		var err error
+		switch age := useCase.now().Sub(transfer.CreatedAt()); {
+		case age >= useCase.maxAge:
+			// Expired transfers are failed even while the circuit is open.
+			err = useCase.fail(ctx, transfer, fmt.Errorf("not sent within %s", useCase.maxAge))
+		case gatewayAvailable(useCase.gatewayMonitor) != nil:
+			output.Deferred++
+			continue
+		default:
+			err = useCase.process(ctx, transfer)
+		}
+		if err != nil {
+			return output, err
+		}
+		switch transfer.Status() {
+		case domain.TransferPending:
+			output.Deferred++
+		case domain.TransferSent:
+			output.Sent++
+		case domain.TransferSettled:
+			output.Settled++
+		case domain.TransferReversed:
+			output.Reversed++
+		default:
+			output.Failed++
+		}
+	}
+	return output, nil
+}
+
+func (useCase *ProcessTransfersUseCase) process(ctx context.Context, transfer *domain.Transfer) error {
+	fromAccount, err := useCase.accountReader.ByID(ctx, transfer.FromID())
+	if err != nil {
+		return err
+	}
+	order := paymentOrderFor(transfer, fromAccount)
+
+	// Another worker may have picked the transfer up since it was listed.
+	sent := *transfer
+	if err := sent.MarkSent(time.Now().UTC()); err != nil {
+		return err
+	}
+	err = useCase.transferWriter.SaveTransfer(ctx, &sent)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return useCase.reload(ctx, transfer)
+	}
+	if err != nil {
+		return err
+	}
+	*transfer = sent
+
+	// External side-effect (STP) via port
+	result, err := useCase.paymentGateway.SendTransfer(ctx, order)
+	if errors.Is(err, ports.ErrPaymentUnavailable) {
+		// Nothing was sent (e.g. the circuit opened, or a trial call is in flight).
+		return useCase.update(ctx, transfer, func(ctx context.Context, stored *domain.Transfer) error {
+			if err := stored.Requeue(time.Now().UTC()); err != nil {
+				return err
+			}
+			return useCase.transferWriter.SaveTransfer(ctx, stored)
+		})
+	}
+	if err != nil && outcomeUnknown(err) {
+		return useCase.awaitOutcome(context.WithoutCancel(ctx), transfer, err)
+	}
+	var rejection *ports.PaymentError
+	if errors.As(err, &rejection) {
+		return useCase.reject(ctx, transfer, rejection)
+	}
+	if err != nil {
+		return useCase.fail(ctx, transfer, err)
+	}
+	acceptedAt, settledAt := result.AcceptedAt, result.SettledAt
+	if acceptedAt.IsZero() {
+		acceptedAt = time.Now().UTC()
+	}
+	if result.Status == ports.PaymentAccepted {
+		return useCase.recordAcceptance(ctx, transfer, result.RailReference, acceptedAt)
+	}
+	if settledAt.IsZero() {
+		settledAt = acceptedAt
+	}
+	err = useCase.update(ctx, transfer, func(ctx context.Context, stored *domain.Transfer) error {
+		if err := stored.RecordAcceptance(result.RailReference, acceptedAt); err != nil {
+			return err
+		}
+		return useCase.settlement.settle(ctx, stored, settledAt)
+	})
+	if err != nil {
+		return useCase.compensate(ctx, transfer, order, err)
+	}
+	return nil
+}
+
+// update applies change to a freshly read copy of transfer inside a unit of
+// work, and refreshes transfer with what was stored. While the worker waited
+// on the rail the status callback may have settled or returned the transfer:
+// STP's word wins, so a transfer whose status moved on since the worker saw
+// it is left as it is. Conflicts with concurrent writers are retried on a new
+// read.
+func (useCase *ProcessTransfersUseCase) update(ctx context.Context, transfer *domain.Transfer, change func(ctx context.Context, stored *domain.Transfer) error) error {
+	var updated *domain.Transfer
+	err := retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			stored, err := useCase.transferReader.TransferByID(ctx, transfer.ID)
+			if err != nil {
+				return err
+			}
+			updated = stored
+			if stored.Status() != transfer.Status() {
+				return nil
+			}
+			return change(ctx, stored)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	*transfer = *updated
+	return nil
+}
+
+// reload replaces transfer with its stored copy.
+func (useCase *ProcessTransfersUseCase) reload(ctx context.Context, transfer *domain.Transfer) error {
+	stored, err := useCase.transferReader.TransferByID(ctx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	*transfer = *stored
+	return nil
+}
+
+// paymentOrderFor builds the SPEI order; the transfer ID is the tracking key.
+func paymentOrderFor(transfer *domain.Transfer, fromAccount *domain.Account) ports.PaymentOrder {
+	return ports.PaymentOrder{
+		TrackingKey:      transfer.TrackingKey(),
+		OriginCLABE:      fromAccount.CLABE(),
+		OriginName:       fromAccount.HolderName(),
+		BeneficiaryCLABE: transfer.Beneficiary().CLABE().String(),
+		BeneficiaryName:  transfer.Beneficiary().Name(),
+		Concept:          transfer.PaymentDetails().Concept,
+		Reference:        transfer.PaymentDetails().Reference,
+		Amount:           transfer.Amount(),
+	}
+}
+
+// outcomeUnknown reports whether the rail may have taken the order despite
+// err: the gateway says so (see PaymentError.OutcomeUnknown), or the call was
+// cancelled midway.
+func outcomeUnknown(err error) bool {
+	return errors.Is(err, ports.ErrPaymentOutcomeUnknown) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// awaitOutcome leaves the transfer SENT with its hold, so the funds cannot be
+// spent or released while STP may still settle it; the status callback
+// resolves it. The record lets someone follow up if the callback never comes.
+func (useCase *ProcessTransfersUseCase) awaitOutcome(ctx context.Context, transfer *domain.Transfer, cause error) error {
+	return useCase.inconsistencies.RecordInconsistency(ctx, ports.Inconsistency{
+		TransferID: transfer.ID,
+		Reason:     "outcome of the order on STP is unknown: " + cause.Error(),
+		Resolution: "transfer left SENT with funds held until STP reports its status",
+		DetectedAt: time.Now().UTC(),
+	})
+}
+
+// gatewayAvailable fails fast while the payment gateway's circuit is open.
+func gatewayAvailable(monitor ports.GatewayMonitor) error {
+	health := monitor.Health()
+	if health.State == ports.CircuitOpen {
+		return &ports.GatewayUnavailableError{RetryAfter: health.RetryAfter}
+	}
+	return nil
+}
+
+// compensate handles "STP says OK, our books say no": it asks STP to reverse
+// the transfer and always leaves a record for manual review. If the reversal
+// fails too, the transfer stays SENT with its hold in place so the funds
+// cannot be spent until someone resolves it.
+func (useCase *ProcessTransfersUseCase) compensate(ctx context.Context, transfer *domain.Transfer, order ports.PaymentOrder, cause error) error {
+	inconsistency := ports.Inconsistency{
+		TransferID: transfer.ID,
+		Reason:     "stp accepted transfer but books could not be updated: " + cause.Error(),
+		DetectedAt: time.Now().UTC(),
+	}
+
+	result, err := useCase.paymentGateway.ReverseTransfer(ctx, order)
+	if err == nil && result.Status != ports.PaymentSettled {
+		err = fmt.Errorf("reversal not settled: %s", result.Status)
+	}
+	if err != nil {
+		inconsistency.Resolution = "reversal failed, transfer left SENT with funds held: " + err.Error()
+		return useCase.inconsistencies.RecordInconsistency(ctx, inconsistency)
+	}
+
+	inconsistency.Resolution = "reversed on STP, hold released"
+	if err := useCase.inconsistencies.RecordInconsistency(ctx, inconsistency); err != nil {
+		return err
+	}
+	return useCase.releaseHold(ctx, transfer, func(stored *domain.Transfer) error {
+		return stored.MarkReversed(cause.Error(), time.Now().UTC())
+	})
+}
+
+// recordAcceptance keeps the rail's reference on a transfer that settles
+// later. The status callback may already have moved it on, so the transfer is
+// read again and only the reference is written.
+func (useCase *ProcessTransfersUseCase) recordAcceptance(ctx context.Context, transfer *domain.Transfer, railReference string, acceptedAt time.Time) error {
+	return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		stored, err := useCase.transferReader.TransferByID(ctx, transfer.ID)
+		if err != nil {
+			return err
+		}
+		if err := stored.RecordAcceptance(railReference, acceptedAt); err != nil {
+			return err
+		}
+		if err := useCase.transferWriter.SaveTransfer(ctx, stored); err != nil {
+			return err
+		}
+		*transfer = *stored
+		return nil
+	})
+}
+
+// reject records the rail's refusal: a retryable one leaves the transfer
+// PENDING with its hold for a later batch, a permanent one fails it.
+func (useCase *ProcessTransfersUseCase) reject(ctx context.Context, transfer *domain.Transfer, rejection *ports.PaymentError) error {
+	railRejection := domain.RailRejection{Code: string(rejection.Code), Retryable: rejection.Retryable(), Reason: rejection.Reason}
+	if railRejection.Retryable && transfer.Attempts() >= useCase.maxAttempts {
+		return useCase.fail(ctx, transfer, fmt.Errorf("gave up after %d attempts: %s: %s", transfer.Attempts(), railRejection.Code, railRejection.Reason))
+	}
+	if !railRejection.Retryable {
+		return useCase.releaseHold(ctx, transfer, func(stored *domain.Transfer) error {
+			return stored.MarkRejected(railRejection, time.Now().UTC())
+		})
+	}
+	return useCase.update(ctx, transfer, func(ctx context.Context, stored *domain.Transfer) error {
+		if err := stored.MarkRejected(railRejection, time.Now().UTC()); err != nil {
+			return err
+		}
+		return useCase.transferWriter.SaveTransfer(ctx, stored)
+	})
+}
+
+// fail releases the hold and records the reason on the transfer.
+// It only returns an error when that bookkeeping itself cannot be saved.
+func (useCase *ProcessTransfersUseCase) fail(ctx context.Context, transfer *domain.Transfer, cause error) error {
+	return useCase.releaseHold(ctx, transfer, func(stored *domain.Transfer) error {
+		return stored.MarkFailed(cause.Error(), time.Now().UTC())
+	})
+}
+
+// releaseHold moves the transfer to its final status with finish, frees its
+// funds and saves both atomically.
+func (useCase *ProcessTransfersUseCase) releaseHold(ctx context.Context, transfer *domain.Transfer, finish func(stored *domain.Transfer) error) error {
+	return useCase.update(ctx, transfer, func(ctx context.Context, stored *domain.Transfer) error {
+		if err := finish(stored); err != nil {
+			return err
+		}
+		fromAccount, err := useCase.accountReader.ByID(ctx, stored.FromID())
+		if err != nil {
+			return err
+		}
+		if err := fromAccount.ReleaseHold(stored.ID); err != nil {
+			return err
+		}
+		if err := useCase.accountWriter.Save(ctx, fromAccount); err != nil {
+			return err
+		}
+		return useCase.transferWriter.SaveTransfer(ctx, stored)
+	})
+}
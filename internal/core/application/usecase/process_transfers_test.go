@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/adapters/out/stp"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/platform/backoff"
+	"hexagonal-bank/internal/platform/circuitbreaker"
+	"hexagonal-bank/internal/platform/logging"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubGateway answers every call with the configured outcome and counts reversals.
+type stubGateway struct {
+	sendStatus    ports.PaymentStatus
+	sendErr       error
+	reverseStatus ports.PaymentStatus
+	reverseErr    error
+	reversals     int
+}
+
+func (gateway *stubGateway) SendTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	if gateway.sendErr != nil {
+		return ports.PaymentResult{}, gateway.sendErr
+	}
+	return ports.PaymentResult{TrackingKey: order.TrackingKey, RailReference: "3000001", Status: gateway.sendStatus}, nil
+}
+
+func (gateway *stubGateway) ReverseTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	gateway.reversals++
+	if gateway.reverseErr != nil {
+		return ports.PaymentResult{}, gateway.reverseErr
+	}
+	return ports.PaymentResult{TrackingKey: order.TrackingKey, Status: gateway.reverseStatus}, nil
+}
+
+// gatewayHealth is a GatewayMonitor reporting a fixed state.
+type gatewayHealth ports.GatewayHealth
+
+func (health gatewayHealth) Health() ports.GatewayHealth { return ports.GatewayHealth(health) }
+
+var closedCircuit = gatewayHealth{State: ports.CircuitClosed}
+
+// instantClock never waits.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time                                          { return time.Now() }
+func (instantClock) Sleep(ctx context.Context, duration time.Duration) error { return ctx.Err() }
+
+// failingLedgerWriter rejects every entry, simulating a broken database.
+type failingLedgerWriter struct{}
+
+func (failingLedgerWriter) Post(ctx context.Context, entry ledger.Entry) error {
+	return errors.New("disk full")
+}
+
+// carolCLABE is an account at another bank (BBVA), unknown to our books.
+const carolCLABE = "012180000118359713"
+
+func mxn(cents int64) domain.Money {
+	money, _ := domain.NewMoney(cents, domain.MXN)
+	return money
+}
+
+type transferFixture struct {
+	accounts        *memory.AccountRepository
+	transfers       *memory.TransferRepository
+	ledger          *memory.LedgerRepository
+	inconsistencies *memory.InconsistencyLog
+	fromID, toID    string
+	transferID      string
+}
+
+// newTransferFixture opens Alice and Bob, funds Alice with 1000 and registers
+// a PENDING outbound transfer of 400 to Carol at another bank.
+func newTransferFixture(t *testing.T) *transferFixture {
+	t.Helper()
+	ctx := context.Background()
+	fixture := &transferFixture{
+		accounts:        memory.NewAccountRepo(),
+		transfers:       memory.NewTransferRepo(),
+		ledger:          memory.NewLedgerRepo(),
+		inconsistencies: memory.NewInconsistencyLog(),
+	}
+	openAccount := NewOpenAccountUseCase(fixture.accounts)
+	alice, err := openAccount.Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719"})
+	if err != nil {
+		t.Fatalf("open alice: %v", err)
+	}
+	bob, err := openAccount.Execute(ctx, OpenAccountInput{HolderName: "Bob", CLABE: "002180000118359710"})
+	if err != nil {
+		t.Fatalf("open bob: %v", err)
+	}
+	fixture.fromID, fixture.toID = alice.ID, bob.ID
+
+	deposit := NewDepositMoneyUseCase(fixture.accounts, fixture.accounts, fixture.ledger, fixture.ledger, fixture.accounts)
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: alice.ID, Amount: mxn(1000)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	outbound := NewSendOutboundTransferUseCase(fixture.accounts, fixture.accounts, fixture.transfers, fixture.accounts, closedCircuit)
+	output, err := outbound.Execute(ctx, OutboundTransferInput{
+		FromID: alice.ID, BeneficiaryCLABE: carolCLABE, BeneficiaryName: "Carol",
+		Concept: "rent", Reference: 1234567, Amount: mxn(400),
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	fixture.transferID = output.ID
+	return fixture
+}
+
+func (fixture *transferFixture) process(t *testing.T, writer ports.LedgerWriter, gateway ports.PaymentGateway) ProcessTransfersOutput {
+	t.Helper()
+	return fixture.run(t, fixture.processor(writer, gateway))
+}
+
+func (fixture *transferFixture) processor(writer ports.LedgerWriter, gateway ports.PaymentGateway) *ProcessTransfersUseCase {
+	monitor, ok := gateway.(ports.GatewayMonitor)
+	if !ok {
+		monitor = closedCircuit
+	}
+	return NewProcessTransfersUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+		fixture.ledger, writer, fixture.accounts, gateway, monitor, memory.NewOutbox(), fixture.inconsistencies,
+	)
+}
+
+func (fixture *transferFixture) run(t *testing.T, useCase *ProcessTransfersUseCase) ProcessTransfersOutput {
+	t.Helper()
+	output, err := useCase.Execute(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	return output
+}
+
+func (fixture *transferFixture) assertState(t *testing.T, wantStatus domain.TransferStatus, wantBalance, wantAvailable int64) {
+	t.Helper()
+	ctx := context.Background()
+	transfer, _ := fixture.transfers.TransferByID(ctx, fixture.transferID)
+	if transfer.Status() != wantStatus {
+		t.Fatalf("status want=%s got=%s (%s)", wantStatus, transfer.Status(), transfer.FailureReason())
+	}
+	from, _ := fixture.accounts.ByID(ctx, fixture.fromID)
+	if from.Balance().Amount() != wantBalance || from.AvailableBalance().Amount() != wantAvailable {
+		t.Fatalf("from balance want=%d/%d got=%d/%d", wantBalance, wantAvailable, from.Balance().Amount(), from.AvailableBalance().Amount())
+	}
+}
+
+func TestProcessTransfersSettles(t *testing.T) {
+	fixture := newTransferFixture(t)
+	output := fixture.process(t, fixture.ledger, &stubGateway{sendStatus: ports.PaymentSettled})
+
+	if output.Settled != 1 {
+		t.Fatalf("want 1 settled got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferSettled, 600, 600)
+	if balance, _ := fixture.ledger.BalanceOf(context.Background(), ledger.SPEIClearingAccount); balance != 400 {
+		t.Fatalf("SPEI clearing balance want=400 got=%d", balance)
+	}
+}
+
+func TestProcessTransfersRetriesLaterWhenSTPIsUnavailable(t *testing.T) {
+	fixture := newTransferFixture(t)
+	output := fixture.process(t, fixture.ledger, &stubGateway{sendErr: &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: "outage"}})
+
+	if output.Deferred != 1 {
+		t.Fatalf("want 1 deferred got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferPending, 1000, 600)
+	transfer, _ := fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	if rejection := newTransferOutput(transfer).Rejection; rejection == nil || rejection.Code != "RAIL_UNAVAILABLE" || rejection.Kind != "RETRYABLE" {
+		t.Fatalf("want the retryable rejection on the transfer, got %+v", rejection)
+	}
+
+	// The next batch sends it again.
+	if output := fixture.process(t, fixture.ledger, &stubGateway{sendStatus: ports.PaymentSettled}); output.Settled != 1 {
+		t.Fatalf("want 1 settled got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferSettled, 600, 600)
+	transfer, _ = fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	if _, rejected := transfer.Rejection(); rejected || transfer.RailReference() != "3000001" || transfer.AcceptedAt().IsZero() || transfer.SettledAt().IsZero() {
+		t.Fatalf("want the rail's reference and timestamps, got %+v", newTransferOutput(transfer))
+	}
+}
+
+func TestProcessTransfersRecordsRejectionCode(t *testing.T) {
+	fixture := newTransferFixture(t)
+	rejection := &ports.PaymentError{Code: ports.RejectBeneficiaryNameMismatch, Reason: "nombre no coincide"}
+	if !errors.Is(rejection, ports.ErrPaymentRejected) || errors.Is(rejection, ports.ErrPaymentRetryable) {
+		t.Fatalf("a name mismatch is permanent: %v", rejection)
+	}
+	fixture.process(t, fixture.ledger, &stubGateway{sendErr: rejection})
+
+	fixture.assertState(t, domain.TransferFailed, 1000, 1000)
+	transfer, _ := fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	if !strings.Contains(transfer.FailureReason(), string(ports.RejectBeneficiaryNameMismatch)) {
+		t.Fatalf("failure reason must carry the rejection code, got %q", transfer.FailureReason())
+	}
+	if rejection := newTransferOutput(transfer).Rejection; rejection == nil || rejection.Kind != "PERMANENT" {
+		t.Fatalf("want the permanent rejection on the transfer, got %+v", rejection)
+	}
+}
+
+func TestProcessTransfersReversesWhenPersistenceFails(t *testing.T) {
+	fixture := newTransferFixture(t)
+	gateway := &stubGateway{sendStatus: ports.PaymentSettled, reverseStatus: ports.PaymentSettled}
+	output := fixture.process(t, failingLedgerWriter{}, gateway)
+
+	if output.Reversed != 1 || gateway.reversals != 1 {
+		t.Fatalf("want 1 reversal got output=%+v reversals=%d", output, gateway.reversals)
+	}
+	fixture.assertState(t, domain.TransferReversed, 1000, 1000)
+	if balance, _ := fixture.ledger.BalanceOf(context.Background(), ledger.SPEIClearingAccount); balance != 0 {
+		t.Fatalf("nothing must be posted, clearing balance=%d", balance)
+	}
+	if recorded := fixture.inconsistencies.All(); len(recorded) != 1 || recorded[0].TransferID != fixture.transferID {
+		t.Fatalf("want one inconsistency for the transfer, got %+v", recorded)
+	}
+}
+
+func TestProcessTransfersKeepsHoldWhenReversalFails(t *testing.T) {
+	fixture := newTransferFixture(t)
+	gateway := &stubGateway{sendStatus: ports.PaymentSettled, reverseErr: &ports.PaymentError{Code: ports.RejectTimeout, Reason: "no answer"}}
+	fixture.process(t, failingLedgerWriter{}, gateway)
+
+	// Money is out on STP but not in our books: freeze the funds, escalate.
+	fixture.assertState(t, domain.TransferSent, 1000, 600)
+	if recorded := fixture.inconsistencies.All(); len(recorded) != 1 {
+		t.Fatalf("want one inconsistency, got %+v", recorded)
+	}
+}
+
+func TestOutboundTransfersWaitWhileCircuitOpen(t *testing.T) {
+	fixture := newTransferFixture(t)
+	outage := &stubGateway{sendErr: &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: "outage"}}
+	gateway := stp.NewCircuitBreaker(logging.NewStd(), outage, circuitbreaker.Settings{FailureThreshold: 1, CoolDown: time.Minute})
+	fixture.process(t, fixture.ledger, gateway) // the failure opens the circuit
+	fixture.assertState(t, domain.TransferPending, 1000, 600)
+
+	input := OutboundTransferInput{
+		FromID: fixture.fromID, BeneficiaryCLABE: carolCLABE, BeneficiaryName: "Carol",
+		Concept: "rent", Reference: 1234567, Amount: mxn(400),
+	}
+	outbound := NewSendOutboundTransferUseCase(fixture.accounts, fixture.accounts, fixture.transfers, fixture.accounts, gateway)
+	var unavailable *ports.GatewayUnavailableError
+	if _, err := outbound.Execute(context.Background(), input); !errors.As(err, &unavailable) || unavailable.RetryAfter <= 0 {
+		t.Fatalf("want GatewayUnavailableError with a retry delay, got %v", err)
+	}
+
+	// Accepted before the outage was known: it waits for the rail.
+	queued, err := NewSendOutboundTransferUseCase(fixture.accounts, fixture.accounts, fixture.transfers, fixture.accounts, closedCircuit).Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	fixture.transferID = queued.ID
+	if output := fixture.process(t, fixture.ledger, gateway); output.Deferred != 2 {
+		t.Fatalf("want 2 deferred got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferPending, 1000, 200)
+}
+
+func TestProcessTransfersGivesUpAfterMaxAttempts(t *testing.T) {
+	fixture := newTransferFixture(t)
+	busy := &stubGateway{sendErr: &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: "busy"}}
+	useCase := fixture.processor(fixture.ledger, busy).WithRetryLimits(2, time.Hour)
+
+	if output := fixture.run(t, useCase); output.Deferred != 1 {
+		t.Fatalf("first attempt: want 1 deferred got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferPending, 1000, 600)
+	if output := fixture.run(t, useCase); output.Failed != 1 {
+		t.Fatalf("second attempt: want 1 failed got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferFailed, 1000, 1000)
+	transfer, _ := fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	if transfer.Attempts() != 2 || !strings.Contains(transfer.FailureReason(), "gave up after 2 attempts: RAIL_UNAVAILABLE") {
+		t.Fatalf("unexpected transfer: attempts=%d reason=%q", transfer.Attempts(), transfer.FailureReason())
+	}
+}
+
+func TestProcessTransfersExpiresTransfersWhileCircuitOpen(t *testing.T) {
+	fixture := newTransferFixture(t)
+	later := time.Now().Add(DefaultMaxPendingAge)
+	open := gatewayHealth{State: ports.CircuitOpen, RetryAfter: time.Minute}
+	useCase := NewProcessTransfersUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+		fixture.ledger, fixture.ledger, fixture.accounts, &stubGateway{}, open, memory.NewOutbox(), fixture.inconsistencies,
+	)
+	if output := fixture.run(t, useCase); output.Deferred != 1 {
+		t.Fatalf("want 1 deferred got %+v", output)
+	}
+	if output := fixture.run(t, useCase.WithClock(func() time.Time { return later })); output.Failed != 1 {
+		t.Fatalf("want 1 failed got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferFailed, 1000, 1000)
+}
+
+func TestProcessTransfersDefersWhenGatewayRefusesToCall(t *testing.T) {
+	fixture := newTransferFixture(t)
+	// e.g. a HALF_OPEN circuit with its trial call already in flight
+	output := fixture.process(t, fixture.ledger, &stubGateway{sendErr: &ports.GatewayUnavailableError{}})
+
+	if output.Deferred != 1 || output.Failed != 0 {
+		t.Fatalf("want 1 deferred got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferPending, 1000, 600)
+	if output := fixture.process(t, fixture.ledger, &stubGateway{sendStatus: ports.PaymentSettled}); output.Settled != 1 {
+		t.Fatalf("want 1 settled on the next batch got %+v", output)
+	}
+}
+
+// TestProcessTransfersKeepsHoldWhenOutcomeIsUnknown sends through the STP
+// client to the stand-in, which registers the order on the last attempt but
+// never answers it: the money may be on its way, so it must stay held.
+func TestProcessTransfersKeepsHoldWhenOutcomeIsUnknown(t *testing.T) {
+	key, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	standIn := stp.NewStandIn(&key.PublicKey)
+	defer standIn.Close()
+	config := stp.DefaultClientConfig()
+	config.BaseURL, config.Company, config.PrivateKey = standIn.URL, "HEXBANK", key
+	config.Retry.Strategy = backoff.ConstantBackoff(0)
+	client, err := stp.NewClient(logging.NewStd(), config)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	fixture := newTransferFixture(t)
+	standIn.FailNext(config.Retry.MaxAttempts - 1)
+	standIn.LoseNextAnswers(1)
+	if output := fixture.process(t, fixture.ledger, client); output.Sent != 1 || standIn.Registered() != 1 {
+		t.Fatalf("want 1 sent and registered got %+v registered=%d", output, standIn.Registered())
+	}
+	fixture.assertState(t, domain.TransferSent, 1000, 600)
+	if recorded := fixture.inconsistencies.All(); len(recorded) != 1 || recorded[0].TransferID != fixture.transferID {
+		t.Fatalf("want one inconsistency for the transfer, got %+v", recorded)
+	}
+
+	// STP settled it after all and says so through the status webhook.
+	useCase := NewUpdateTransferStatusUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+		fixture.ledger, fixture.ledger, fixture.accounts, memory.NewOutbox(), fixture.inconsistencies,
+	)
+	transfer, _ := fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	if _, err := useCase.Execute(context.Background(), TransferStatusInput{TrackingKey: transfer.TrackingKey(), Status: domain.TransferSettled}); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+	fixture.assertState(t, domain.TransferSettled, 600, 600)
+}
+
+func TestProcessTransfersTreatsDuplicateOrderAsSent(t *testing.T) {
+	fixture := newTransferFixture(t)
+	// A requeued transfer whose earlier attempt did reach the rail.
+	duplicate := &ports.PaymentError{Code: ports.RejectDuplicateOrder, Reason: "Clave de rastreo duplicada"}
+	if !errors.Is(duplicate, ports.ErrPaymentOutcomeUnknown) || errors.Is(duplicate, ports.ErrPaymentRejected) || !duplicate.Retryable() {
+		t.Fatalf("a duplicate order is an unknown outcome, not a rejection: %v", duplicate)
+	}
+	fixture.process(t, fixture.ledger, &stubGateway{sendErr: duplicate})
+
+	fixture.assertState(t, domain.TransferSent, 1000, 600)
+	if recorded := fixture.inconsistencies.All(); len(recorded) != 1 {
+		t.Fatalf("want one inconsistency, got %+v", recorded)
+	}
+}
+
+func TestSendOutboundTransferRejectsLocalBeneficiary(t *testing.T) {
+	fixture := newTransferFixture(t)
+	outbound := NewSendOutboundTransferUseCase(fixture.accounts, fixture.accounts, fixture.transfers, fixture.accounts, closedCircuit)
+
+	cases := []struct {
+		name    string
+		input   OutboundTransferInput
+		wantErr error
+	}{
+		{"local CLABE", OutboundTransferInput{BeneficiaryCLABE: "002180000118359710", BeneficiaryName: "Bob"}, domain.ErrLocalBeneficiary},
+		{"no beneficiary name", OutboundTransferInput{BeneficiaryCLABE: carolCLABE}, domain.ErrInvalidBeneficiary},
+		{"no concept", OutboundTransferInput{BeneficiaryCLABE: carolCLABE, BeneficiaryName: "Carol", Reference: 1}, domain.ErrInvalidPaymentDetails},
+		{"reference too long", OutboundTransferInput{BeneficiaryCLABE: carolCLABE, BeneficiaryName: "Carol", Concept: "rent", Reference: 12345678}, domain.ErrInvalidPaymentDetails},
+	}
+	for _, tc := range cases {
+		tc.input.FromID, tc.input.Amount = fixture.fromID, mxn(100)
+		if _, err := outbound.Execute(context.Background(), tc.input); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %v got %v", tc.name, tc.wantErr, err)
+		}
+	}
+}
+
+// TestProcessTransfersThroughFakeSTP runs the SPEI flow against the fake rail
+// with a scripted, seeded configuration, so every run takes the same path.
+func TestProcessTransfersThroughFakeSTP(t *testing.T) {
+	scripted := func(script ...stp.Outcome) *stp.FakeSTP {
+		config := stp.DefaultFakeSTPConfig()
+		config.Random = rand.New(rand.NewSource(7))
+		config.Script = script
+		config.Clock = instantClock{}
+		return stp.NewFakeSTP(logging.NewStd(), config)
+	}
+
+	fixture := newTransferFixture(t)
+	fixture.process(t, fixture.ledger, scripted(stp.Unavailable, stp.Unavailable, stp.Succeed))
+	fixture.assertState(t, domain.TransferSettled, 600, 600)
+
+	fixture = newTransferFixture(t)
+	fixture.process(t, fixture.ledger, scripted(stp.Unavailable, stp.Reject(ports.RejectBeneficiaryNameMismatch, "nombre no coincide")))
+	fixture.assertState(t, domain.TransferFailed, 1000, 1000)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"time"
+)
+
+type QuoteExchangeRateInput struct {
+	From string
+	To   string
+}
+
+type QuoteExchangeRateOutput struct {
+	QuoteID   string    `json:"quote_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      string    `json:"rate"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// QuoteExchangeRateUseCase gives clients a quote they can lock a transfer to.
+type QuoteExchangeRateUseCase struct {
+	fxRateProvider ports.FXRateProvider
+}
+
+func NewQuoteExchangeRateUseCase(fxRateProvider ports.FXRateProvider) *QuoteExchangeRateUseCase {
+	return &QuoteExchangeRateUseCase{fxRateProvider: fxRateProvider}
+}
+
+func (useCase *QuoteExchangeRateUseCase) Execute(ctx context.Context, input QuoteExchangeRateInput) (QuoteExchangeRateOutput, error) {
+	from, err := domain.ParseCurrency(input.From)
+	if err != nil {
+		return QuoteExchangeRateOutput{}, err
+	}
+	to, err := domain.ParseCurrency(input.To)
+	if err != nil {
+		return QuoteExchangeRateOutput{}, err
+	}
+	quote, err := useCase.fxRateProvider.Quote(ctx, from, to)
+	if err != nil {
+		return QuoteExchangeRateOutput{}, err
+	}
+	return QuoteExchangeRateOutput{
+		QuoteID:   quote.ID(),
+		From:      string(quote.From()),
+		To:        string(quote.To()),
+		Rate:      quote.Rate(),
+		ExpiresAt: quote.ExpiresAt(),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/shared/id"
+	"time"
+)
+
+// IncomingTransferInput is a SPEI payment another bank sent to one of our CLABEs.
+type IncomingTransferInput struct {
+	TrackingKey      string    // the sender's clave de rastreo
+	OperationDate    time.Time // the rail's fecha de operación
+	BeneficiaryCLABE string
+	OriginCLABE      string
+	OriginName       string
+	OriginBankCode   string
+	Concept          string
+	Reference        int
+	Amount           domain.Money // MXN
+}
+
+// ReceiveIncomingTransferUseCase credits the account whose CLABE the payment
+// was sent to. The rail may deliver the same payment more than once, so it is
+// deduplicated by sending bank, tracking key and operation date: a repeat
+// returns the transfer already booked and credits nothing.
+// Errors tell why the payment cannot be accepted (unknown, frozen or closed
+// account, wrong currency), so the adapter can return it to the sender.
+type ReceiveIncomingTransferUseCase struct {
+	accountReader  ports.AccountReader
+	accountWriter  ports.AccountWriter
+	transferReader ports.TransferReader
+	transferWriter ports.TransferWriter
+	ledgerReader   ports.LedgerReader
+	ledgerWriter   ports.LedgerWriter
+	unitOfWork     ports.UnitOfWork
+	outbox         ports.Outbox
+}
+
+func NewReceiveIncomingTransferUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	transferReader ports.TransferReader,
+	transferWriter ports.TransferWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+	outbox ports.Outbox,
+) *ReceiveIncomingTransferUseCase {
+	return &ReceiveIncomingTransferUseCase{
+		accountReader:  accountReader,
+		accountWriter:  accountWriter,
+		transferReader: transferReader,
+		transferWriter: transferWriter,
+		ledgerReader:   ledgerReader,
+		ledgerWriter:   ledgerWriter,
+		unitOfWork:     unitOfWork,
+		outbox:         outbox,
+	}
+}
+
+func (useCase *ReceiveIncomingTransferUseCase) Execute(ctx context.Context, input IncomingTransferInput) (TransferOutput, error) {
+	clabe, err := domain.NewCLABE(input.BeneficiaryCLABE)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	originator := domain.Originator{CLABE: input.OriginCLABE, Name: input.OriginName, BankCode: input.OriginBankCode}
+	details := domain.PaymentDetails{Concept: input.Concept, Reference: input.Reference}
+
+	key := domain.InboundSPEIKey(input.OriginBankCode, input.TrackingKey, input.OperationDate)
+
+	// The key is checked inside the unit of work: units of work are
+	// serialized, so two deliveries of the same payment cannot both credit.
+	var received *domain.Transfer
+	err = retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			existing, err := useCase.transferReader.TransferBySPEIKey(ctx, key)
+			if err == nil {
+				received = existing
+				return nil
+			}
+			if !errors.Is(err, domain.ErrTransferNotFound) {
+				return err
+			}
+			account, err := useCase.accountReader.ByCLABE(ctx, clabe.String())
+			if err != nil {
+				return err
+			}
+			transfer, err := domain.NewInboundTransfer(id.New(), input.TrackingKey, input.OperationDate, account.ID, originator, details, input.Amount, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			if err := useCase.settle(ctx, account, transfer); err != nil {
+				return err
+			}
+			received = transfer
+			return nil
+		})
+	})
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	return newTransferOutput(received), nil
+}
+
+func (useCase *ReceiveIncomingTransferUseCase) settle(ctx context.Context, account *domain.Account, transfer *domain.Transfer) error {
+	// Domain rules first
+	if err := account.Credit(transfer.Amount()); err != nil {
+		return err
+	}
+	// The rail owes us the money until Banxico settles with the sending bank.
+	entry, err := ledger.NewEntry(id.New(), ledger.KindTransfer, time.Now().UTC(),
+		ledger.DebitLeg(ledger.SPEIClearingAccount, transfer.Amount()),
+		ledger.CreditLeg(account.ID, transfer.Amount()),
+	)
+	if err != nil {
+		return err
+	}
+	if err := reconcileEntry(ctx, useCase.ledgerReader, entry, account); err != nil {
+		return err
+	}
+	if err := transfer.MarkSettled(time.Now().UTC()); err != nil {
+		return err
+	}
+
+	if err := useCase.accountWriter.Save(ctx, account); err != nil {
+		return err
+	}
+	if err := useCase.ledgerWriter.Post(ctx, entry); err != nil {
+		return err
+	}
+	if err := useCase.transferWriter.CreateTransfer(ctx, transfer); err != nil {
+		return err
+	}
+	// Relayed to the EventPublisher after commit (see worker.OutboxRelay)
+	return useCase.outbox.Enqueue(ctx, "transfer.received", map[string]any{
+		"transfer_id": transfer.ID, "kind": transfer.Kind(), "to_id": transfer.ToID(),
+		"tracking_key": transfer.TrackingKey(), "origin_clabe": transfer.Originator().CLABE, "amount": transfer.Amount().String(),
+	})
+}
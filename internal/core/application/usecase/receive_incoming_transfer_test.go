@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+	"time"
+)
+
+var operationDate = time.Date(2025, 10, 16, 0, 0, 0, 0, time.UTC)
+
+func (fixture *transferFixture) receive() *ReceiveIncomingTransferUseCase {
+	return NewReceiveIncomingTransferUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+		fixture.ledger, fixture.ledger, fixture.accounts, memory.NewOutbox(),
+	)
+}
+
+func TestReceiveIncomingTransferCreditsOnce(t *testing.T) {
+	fixture := newTransferFixture(t)
+	ctx := context.Background()
+	input := IncomingTransferInput{
+		TrackingKey: "BBVA2025101600042", OperationDate: operationDate, BeneficiaryCLABE: "002180000118359710",
+		OriginCLABE: carolCLABE, OriginName: "Carol", OriginBankCode: "012",
+		Concept: "invoice 7", Reference: 7, Amount: mxn(250),
+	}
+
+	receive := fixture.receive()
+	first, err := receive.Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if first.Kind != "SPEI_IN" || first.Status != "SETTLED" || first.ToID != fixture.toID {
+		t.Fatalf("unexpected transfer %+v", first)
+	}
+	// STP redelivers until acknowledged; the repeat must not credit again.
+	again, err := receive.Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("redelivery booked a new transfer: %s != %s", again.ID, first.ID)
+	}
+	if bob, _ := fixture.accounts.ByID(ctx, fixture.toID); bob.Balance().Amount() != 250 {
+		t.Fatalf("bob balance want=250 got=%d", bob.Balance().Amount())
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, fixture.toID); balance != 250 {
+		t.Fatalf("ledger bob balance want=250 got=%d", balance)
+	}
+}
+
+func TestReceiveIncomingTransferRejects(t *testing.T) {
+	fixture := newTransferFixture(t)
+	ctx := context.Background()
+	if _, err := NewFreezeAccountUseCase(fixture.accounts, fixture.accounts).Execute(ctx, fixture.toID); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	for _, test := range []struct {
+		clabe string
+		want  error
+	}{
+		{"002180000118359710", domain.ErrAccountFrozen},
+		{"646180000118359710", domain.ErrAccountNotFound},
+		{"002180000118359711", domain.ErrInvalidCLABEControlDigit},
+	} {
+		input := IncomingTransferInput{TrackingKey: "K-" + test.clabe, OperationDate: operationDate, BeneficiaryCLABE: test.clabe, Amount: mxn(100)}
+		if _, err := fixture.receive().Execute(ctx, input); !errors.Is(err, test.want) {
+			t.Errorf("%s: want %v got %v", test.clabe, test.want, err)
+		}
+	}
+	if _, err := fixture.transfers.TransferBySPEIKey(ctx, domain.InboundSPEIKey("", "K-002180000118359710", operationDate)); !errors.Is(err, domain.ErrTransferNotFound) {
+		t.Fatalf("rejected payments are not booked, got %v", err)
+	}
+}
+
+func TestReceiveIncomingTransferKeysBySenderAndDate(t *testing.T) {
+	fixture := newTransferFixture(t)
+	ctx := context.Background()
+	base := IncomingTransferInput{
+		TrackingKey: "BBVA2025101600042", OperationDate: operationDate, BeneficiaryCLABE: "002180000118359710",
+		OriginCLABE: carolCLABE, OriginName: "Carol", OriginBankCode: "012",
+		Concept: "invoice 7", Reference: 7, Amount: mxn(250),
+	}
+	otherBank := base
+	otherBank.OriginBankCode = "014"
+	nextDay := base
+	nextDay.OperationDate = operationDate.AddDate(0, 0, 1)
+	sameKeyAsOurs := base
+	sameKeyAsOurs.TrackingKey = fixture.transferID // the key we sent our own payment with
+
+	seen := map[string]bool{}
+	for _, input := range []IncomingTransferInput{base, otherBank, nextDay, sameKeyAsOurs} {
+		received, err := fixture.receive().Execute(ctx, input)
+		if err != nil {
+			t.Fatalf("receive %+v: %v", input, err)
+		}
+		if seen[received.ID] || received.ID == fixture.transferID {
+			t.Fatalf("%s from %s on %s was taken for a duplicate", input.TrackingKey, input.OriginBankCode, input.OperationDate)
+		}
+		seen[received.ID] = true
+	}
+	if bob, _ := fixture.accounts.ByID(ctx, fixture.toID); bob.Balance().Amount() != 1000 {
+		t.Fatalf("four distinct payments: want 1000 got %d", bob.Balance().Amount())
+	}
+}
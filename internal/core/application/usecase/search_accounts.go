@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"strings"
+)
+
+const (
+	defaultAccountsPageSize = 20
+	maxAccountsPageSize     = 100
+)
+
+// SearchAccountsInput holds raw query values; empty strings mean "any".
+type SearchAccountsInput struct {
+	HolderName  string
+	CLABEPrefix string
+	Status      string // ACTIVE, FROZEN or CLOSED
+	Currency    string
+	MinBalance  *int64 // minor units, inclusive
+	MaxBalance  *int64 // minor units, inclusive
+	Sort        string // id (default), holder_name or balance; prefix "-" for descending
+	Cursor      string
+	Limit       int
+}
+
+type AccountSummary struct {
+	ID           string `json:"id"`
+	HolderName   string `json:"holder_name"`
+	CLABE        string `json:"clabe"`
+	BankName     string `json:"bank_name"`
+	Status       string `json:"status"`
+	Currency     string `json:"currency"`
+	Balance      string `json:"balance"`
+	BalanceCents int64  `json:"balance_cents"`
+}
+
+type SearchAccountsOutput struct {
+	Items      []AccountSummary `json:"items"`
+	Limit      int              `json:"limit"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// SearchAccountsUseCase lists accounts for back-office tools.
+type SearchAccountsUseCase struct {
+	accountQuery ports.AccountQuery
+}
+
+func NewSearchAccountsUseCase(accountQuery ports.AccountQuery) *SearchAccountsUseCase {
+	return &SearchAccountsUseCase{accountQuery: accountQuery}
+}
+
+func (useCase *SearchAccountsUseCase) Execute(ctx context.Context, input SearchAccountsInput) (SearchAccountsOutput, error) {
+	filter, err := accountFilterFrom(input)
+	if err != nil {
+		return SearchAccountsOutput{}, err
+	}
+	page, err := useCase.accountQuery.SearchAccounts(ctx, filter)
+	if err != nil {
+		return SearchAccountsOutput{}, err
+	}
+	items := make([]AccountSummary, 0, len(page.Accounts))
+	for _, account := range page.Accounts {
+		items = append(items, AccountSummary{
+			ID:           account.ID,
+			HolderName:   account.HolderName(),
+			CLABE:        account.CLABE(),
+			BankName:     account.BankAccount().BankName(),
+			Status:       string(account.Status()),
+			Currency:     string(account.Currency()),
+			Balance:      account.Balance().String(),
+			BalanceCents: account.Balance().Amount(),
+		})
+	}
+	return SearchAccountsOutput{Items: items, Limit: filter.Limit, NextCursor: page.NextCursor}, nil
+}
+
+// accountFilterFrom validates the raw input into a port filter.
+func accountFilterFrom(input SearchAccountsInput) (ports.AccountFilter, error) {
+	filter := ports.AccountFilter{
+		HolderName:  strings.TrimSpace(input.HolderName),
+		CLABEPrefix: strings.TrimSpace(input.CLABEPrefix),
+		MinBalance:  input.MinBalance,
+		MaxBalance:  input.MaxBalance,
+		Cursor:      input.Cursor,
+		Limit:       input.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAccountsPageSize
+	}
+	filter.Limit = min(filter.Limit, maxAccountsPageSize)
+
+	switch status := domain.AccountStatus(strings.ToUpper(input.Status)); status {
+	case "", domain.AccountActive, domain.AccountFrozen, domain.AccountClosed:
+		filter.Status = status
+	default:
+		return ports.AccountFilter{}, fmt.Errorf("%w: unknown status %q", ports.ErrInvalidFilter, input.Status)
+	}
+	if input.Currency != "" {
+		currency, err := domain.ParseCurrency(input.Currency)
+		if err != nil {
+			return ports.AccountFilter{}, err
+		}
+		filter.Currency = currency
+	}
+	if filter.MinBalance != nil && filter.MaxBalance != nil && *filter.MinBalance > *filter.MaxBalance {
+		return ports.AccountFilter{}, fmt.Errorf("%w: min_balance above max_balance", ports.ErrInvalidFilter)
+	}
+
+	sort, descending := strings.CutPrefix(input.Sort, "-")
+	switch sortBy := ports.AccountSort(sort); sortBy {
+	case "", ports.SortByID, ports.SortByHolderName, ports.SortByBalance:
+		filter.SortBy, filter.Descending = sortBy, descending
+	default:
+		return ports.AccountFilter{}, fmt.Errorf("%w: cannot sort by %q", ports.ErrInvalidFilter, input.Sort)
+	}
+	return filter, nil
+}
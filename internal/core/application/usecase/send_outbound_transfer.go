@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/shared/id"
+	"time"
+)
+
+// OutboundTransferInput names the source account by ID or, when the ID is
+// empty, by CLABE.
+type OutboundTransferInput struct {
+	FromID           string
+	FromCLABE        string
+	BeneficiaryCLABE string
+	BeneficiaryName  string
+	Concept          string
+	Reference        int
+	Amount           domain.Money // MXN
+}
+
+// SendOutboundTransferUseCase accepts a SPEI transfer to another bank: it
+// reserves the funds with a hold on the source account and registers the
+// transfer as PENDING. Sending it through the PaymentGateway happens
+// asynchronously in ProcessTransfersUseCase; while the gateway's circuit is
+// open, new transfers are refused with *ports.GatewayUnavailableError.
+type SendOutboundTransferUseCase struct {
+	accountReader  ports.AccountReader
+	accountWriter  ports.AccountWriter
+	transferWriter ports.TransferWriter
+	unitOfWork     ports.UnitOfWork
+	gatewayMonitor ports.GatewayMonitor
+}
+
+func NewSendOutboundTransferUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	transferWriter ports.TransferWriter,
+	unitOfWork ports.UnitOfWork,
+	gatewayMonitor ports.GatewayMonitor,
+) *SendOutboundTransferUseCase {
+	return &SendOutboundTransferUseCase{
+		accountReader:  accountReader,
+		accountWriter:  accountWriter,
+		transferWriter: transferWriter,
+		unitOfWork:     unitOfWork,
+		gatewayMonitor: gatewayMonitor,
+	}
+}
+
+func (useCase *SendOutboundTransferUseCase) Execute(ctx context.Context, input OutboundTransferInput) (TransferOutput, error) {
+	if err := gatewayAvailable(useCase.gatewayMonitor); err != nil {
+		return TransferOutput{}, err
+	}
+	fromID, err := resolveAccountID(ctx, useCase.accountReader, input.FromID, input.FromCLABE)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	beneficiary, err := domain.NewBeneficiary(input.BeneficiaryCLABE, input.BeneficiaryName)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	// Money between our own accounts is a book transfer, never a SPEI order.
+	_, err = useCase.accountReader.ByCLABE(ctx, beneficiary.CLABE().String())
+	if err == nil {
+		return TransferOutput{}, domain.ErrLocalBeneficiary
+	}
+	if !errors.Is(err, domain.ErrAccountNotFound) {
+		return TransferOutput{}, err
+	}
+	details := domain.PaymentDetails{Concept: input.Concept, Reference: input.Reference}
+	transfer, err := domain.NewOutboundTransfer(id.New(), fromID, beneficiary, details, input.Amount, time.Now().UTC())
+	if err != nil {
+		return TransferOutput{}, err
+	}
+
+	// The hold (keyed by transfer ID) and the transfer are stored together,
+	// so a PENDING transfer always has its funds reserved.
+	err = retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			fromAccount, err := useCase.accountReader.ByID(ctx, fromID)
+			if err != nil {
+				return err
+			}
+			if err := fromAccount.PlaceHold(transfer.ID, transfer.Amount()); err != nil {
+				return err
+			}
+			if err := useCase.accountWriter.Save(ctx, fromAccount); err != nil {
+				return err
+			}
+			return useCase.transferWriter.CreateTransfer(ctx, transfer)
+		})
+	})
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	return newTransferOutput(transfer), nil
+}
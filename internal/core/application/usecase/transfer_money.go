@@ -2,82 +2,287 @@ package usecase
 
 import (
 	"context"
-	"fmt"
 	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/shared/id"
+	"time"
 )
 
+// TransferInput names each account by internal ID or, when the ID is empty, by CLABE.
 type TransferInput struct {
-	FromID string
-	ToID   string
-	Cents  int64
+	FromID    string
+	FromCLABE string
+	ToID      string
+	ToCLABE   string
+	Amount    domain.Money // in the source account's currency
+	QuoteID   string       // optional FX quote to lock the rate of a cross-currency transfer
 }
 
 type TransferOutput struct {
-	FromBalance int64 `json:"from_balance_cents"`
-	ToBalance   int64 `json:"to_balance_cents"`
+	ID               string           `json:"id"`
+	Kind             string           `json:"kind"`
+	FromID           string           `json:"from_id,omitempty"`
+	ToID             string           `json:"to_id,omitempty"`
+	TrackingKey      string           `json:"tracking_key,omitempty"`
+	BeneficiaryCLABE string           `json:"beneficiary_clabe,omitempty"`
+	BeneficiaryName  string           `json:"beneficiary_name,omitempty"`
+	OriginCLABE      string           `json:"origin_clabe,omitempty"`
+	OriginName       string           `json:"origin_name,omitempty"`
+	Concept          string           `json:"concept,omitempty"`
+	Reference        int              `json:"reference,omitempty"`
+	Amount           string           `json:"amount"`
+	Cents            int64            `json:"cents"`
+	Currency         string           `json:"currency"`
+	CreditAmount     string           `json:"credit_amount"`
+	FXQuoteID        string           `json:"fx_quote_id,omitempty"`
+	FXRate           string           `json:"fx_rate,omitempty"`
+	Status           string           `json:"status"`
+	FailureReason    string           `json:"failure_reason,omitempty"`
+	RailReference    string           `json:"rail_reference,omitempty"` // outbound: STP's order ID
+	Rejection        *RejectionOutput `json:"rejection,omitempty"`      // outbound: the rail's last refusal
+	Attempts         int              `json:"attempts,omitempty"`       // outbound: times sent to the rail
+	AcceptedAt       time.Time        `json:"accepted_at,omitzero"`
+	SettledAt        time.Time        `json:"settled_at,omitzero"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
 }
 
+// RejectionOutput is the rail's last refusal of an outbound transfer. A
+// RETRYABLE one leaves the transfer PENDING for another attempt; a PERMANENT
+// one fails it.
+type RejectionOutput struct {
+	Code   string `json:"code"`
+	Kind   string `json:"kind"` // RETRYABLE or PERMANENT
+	Reason string `json:"reason"`
+}
+
+func newTransferOutput(transfer *domain.Transfer) TransferOutput {
+	output := TransferOutput{
+		ID:            transfer.ID,
+		Kind:          string(transfer.Kind()),
+		FromID:        transfer.FromID(),
+		ToID:          transfer.ToID(),
+		TrackingKey:   transfer.TrackingKey(),
+		Amount:        transfer.Amount().String(),
+		Cents:         transfer.Amount().Amount(),
+		Currency:      string(transfer.Amount().Currency()),
+		CreditAmount:  transfer.CreditAmount().String(),
+		Status:        string(transfer.Status()),
+		FailureReason: transfer.FailureReason(),
+		RailReference: transfer.RailReference(),
+		AcceptedAt:    transfer.AcceptedAt(),
+		SettledAt:     transfer.SettledAt(),
+		CreatedAt:     transfer.CreatedAt(),
+		UpdatedAt:     transfer.UpdatedAt(),
+	}
+	if transfer.Kind() == domain.TransferOutbound {
+		output.BeneficiaryCLABE = transfer.Beneficiary().CLABE().String()
+		output.BeneficiaryName = transfer.Beneficiary().Name()
+		output.Concept = transfer.PaymentDetails().Concept
+		output.Reference = transfer.PaymentDetails().Reference
+		output.Attempts = transfer.Attempts()
+	}
+	if transfer.Kind() == domain.TransferInbound {
+		output.OriginCLABE = transfer.Originator().CLABE
+		output.OriginName = transfer.Originator().Name
+		output.Concept = transfer.PaymentDetails().Concept
+		output.Reference = transfer.PaymentDetails().Reference
+	}
+	if rejection, rejected := transfer.Rejection(); rejected {
+		kind := "PERMANENT"
+		if rejection.Retryable {
+			kind = "RETRYABLE"
+		}
+		output.Rejection = &RejectionOutput{Code: rejection.Code, Kind: kind, Reason: rejection.Reason}
+	}
+	if quote, converted := transfer.Quote(); converted {
+		output.FXQuoteID = quote.ID()
+		output.FXRate = quote.Rate()
+	}
+	return output
+}
+
+// TransferMoneyUseCase moves money between two local accounts (a book
+// transfer). It never involves the payment rail, so it settles immediately:
+// both balances, the journal entry, the transfer and its event are written
+// in one unit of work.
+// Cross-currency transfers are converted at an FX quote (the client's, or a
+// fresh one) whose rate is recorded on the transfer.
+// Transfers to other banks go through SendOutboundTransferUseCase instead.
 type TransferMoneyUseCase struct {
 	accountReader  ports.AccountReader
 	accountWriter  ports.AccountWriter
-	paymentGateway ports.PaymentGateway
-	eventPublisher ports.EventPublisher
+	transferWriter ports.TransferWriter
+	ledgerReader   ports.LedgerReader
+	ledgerWriter   ports.LedgerWriter
+	unitOfWork     ports.UnitOfWork
+	outbox         ports.Outbox
+	fxRateProvider ports.FXRateProvider
 }
 
 func NewTransferMoneyUseCase(
 	accountReader ports.AccountReader,
 	accountWriter ports.AccountWriter,
-	paymentGateway ports.PaymentGateway,
-	eventPublisher ports.EventPublisher,
+	transferWriter ports.TransferWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+	outbox ports.Outbox,
+	fxRateProvider ports.FXRateProvider,
 ) *TransferMoneyUseCase {
 	return &TransferMoneyUseCase{
 		accountReader:  accountReader,
 		accountWriter:  accountWriter,
-		paymentGateway: paymentGateway,
-		eventPublisher: eventPublisher,
+		transferWriter: transferWriter,
+		ledgerReader:   ledgerReader,
+		ledgerWriter:   ledgerWriter,
+		unitOfWork:     unitOfWork,
+		outbox:         outbox,
+		fxRateProvider: fxRateProvider,
 	}
 }
 
 func (useCase *TransferMoneyUseCase) Execute(ctx context.Context, input TransferInput) (TransferOutput, error) {
-	fromAccount, err := useCase.accountReader.ByID(ctx, input.FromID)
+	var err error
+	if input.FromID, err = resolveAccountID(ctx, useCase.accountReader, input.FromID, input.FromCLABE); err != nil {
+		return TransferOutput{}, err
+	}
+	if input.ToID, err = resolveAccountID(ctx, useCase.accountReader, input.ToID, input.ToCLABE); err != nil {
+		return TransferOutput{}, err
+	}
+	now := time.Now().UTC()
+	transfer, err := domain.NewTransfer(id.New(), input.FromID, input.ToID, input.Amount, now)
 	if err != nil {
 		return TransferOutput{}, err
 	}
-
 	toAccount, err := useCase.accountReader.ByID(ctx, input.ToID)
 	if err != nil {
 		return TransferOutput{}, err
 	}
-
-	// Domain rules first
-	if err := fromAccount.Debit(input.Cents); err != nil {
+	if err := toAccount.EnsureActive(); err != nil {
 		return TransferOutput{}, err
 	}
-	if err := toAccount.Credit(input.Cents); err != nil {
-		return TransferOutput{}, err
+	if toAccount.Currency() != input.Amount.Currency() {
+		quote, err := useCase.quoteFor(ctx, input, toAccount.Currency())
+		if err != nil {
+			return TransferOutput{}, err
+		}
+		if err := transfer.ApplyQuote(quote, now); err != nil {
+			return TransferOutput{}, err
+		}
+	} else if input.QuoteID != "" {
+		return TransferOutput{}, domain.ErrQuoteMismatch
 	}
 
-	// External side-effect (STP) via port
-	status, err := useCase.paymentGateway.SendTransfer(ctx, input.FromID, input.ToID, input.Cents)
+	// Settle a copy so a rolled back unit of work leaves transfer untouched;
+	// the whole read-modify-write is replayed on a version conflict.
+	var settled domain.Transfer
+	err = retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		settled = *transfer
+		return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			return useCase.settle(ctx, &settled)
+		})
+	})
 	if err != nil {
 		return TransferOutput{}, err
 	}
-	if status != "OK" {
-		return TransferOutput{}, fmt.Errorf("stp not ok: %s", status)
+	return newTransferOutput(&settled), nil
+}
+
+func (useCase *TransferMoneyUseCase) settle(ctx context.Context, transfer *domain.Transfer) error {
+	fromAccount, err := useCase.accountReader.ByID(ctx, transfer.FromID())
+	if err != nil {
+		return err
+	}
+	toAccount, err := useCase.accountReader.ByID(ctx, transfer.ToID())
+	if err != nil {
+		return err
+	}
+
+	// Domain rules first
+	if err := fromAccount.Debit(transfer.Amount()); err != nil {
+		return err
+	}
+	if err := toAccount.Credit(transfer.CreditAmount()); err != nil {
+		return err
+	}
+	entry, err := ledger.NewEntry(id.New(), ledger.KindTransfer, time.Now().UTC(), transferLegs(fromAccount.ID, toAccount.ID, transfer)...)
+	if err != nil {
+		return err
+	}
+	if err := reconcileEntry(ctx, useCase.ledgerReader, entry, fromAccount, toAccount); err != nil {
+		return err
+	}
+	if err := transfer.MarkSettled(time.Now().UTC()); err != nil {
+		return err
 	}
 
-	// Persist
 	if err := useCase.accountWriter.Save(ctx, fromAccount); err != nil {
-		return TransferOutput{}, err
+		return err
 	}
 	if err := useCase.accountWriter.Save(ctx, toAccount); err != nil {
-		return TransferOutput{}, err
+		return err
 	}
-
-	// Publish integration event (fire-and-forget)
-	_ = useCase.eventPublisher.Publish(ctx, "transfer.completed", map[string]any{
-		"from_id": input.FromID, "to_id": input.ToID, "cents": input.Cents,
+	if err := useCase.ledgerWriter.Post(ctx, entry); err != nil {
+		return err
+	}
+	if err := useCase.transferWriter.CreateTransfer(ctx, transfer); err != nil {
+		return err
+	}
+	// Relayed to the EventPublisher after commit (see worker.OutboxRelay)
+	return useCase.outbox.Enqueue(ctx, "transfer.completed", map[string]any{
+		"transfer_id": transfer.ID, "kind": transfer.Kind(), "from_id": transfer.FromID(), "to_id": transfer.ToID(),
+		"amount": transfer.Amount().String(), "credit_amount": transfer.CreditAmount().String(),
 	})
+}
+
+// transferLegs books a transfer; conversions go through the FX position
+// account so that each currency balances on its own.
+func transferLegs(fromID, toID string, transfer *domain.Transfer) []ledger.Leg {
+	if _, converted := transfer.Quote(); !converted {
+		return []ledger.Leg{
+			ledger.DebitLeg(fromID, transfer.Amount()),
+			ledger.CreditLeg(toID, transfer.Amount()),
+		}
+	}
+	return []ledger.Leg{
+		ledger.DebitLeg(fromID, transfer.Amount()),
+		ledger.CreditLeg(ledger.FXPositionAccount, transfer.Amount()),
+		ledger.DebitLeg(ledger.FXPositionAccount, transfer.CreditAmount()),
+		ledger.CreditLeg(toID, transfer.CreditAmount()),
+	}
+}
 
-	return TransferOutput{FromBalance: fromAccount.Balance(), ToBalance: toAccount.Balance()}, nil
+// resolveAccountID returns accountID, or the ID of the account holding clabe
+// when no ID was given.
+func resolveAccountID(ctx context.Context, accountReader ports.AccountReader, accountID, clabe string) (string, error) {
+	if accountID != "" || clabe == "" {
+		return accountID, nil
+	}
+	parsed, err := domain.NewCLABE(clabe)
+	if err != nil {
+		return "", err
+	}
+	account, err := accountReader.ByCLABE(ctx, parsed.String())
+	if err != nil {
+		return "", err
+	}
+	return account.ID, nil
+}
+
+// quoteFor returns the client's quote when one was given (it must match the
+// currency pair), or a fresh quote otherwise.
+func (useCase *TransferMoneyUseCase) quoteFor(ctx context.Context, input TransferInput, to domain.Currency) (domain.FXQuote, error) {
+	if input.QuoteID == "" {
+		return useCase.fxRateProvider.Quote(ctx, input.Amount.Currency(), to)
+	}
+	quote, err := useCase.fxRateProvider.QuoteByID(ctx, input.QuoteID)
+	if err != nil {
+		return domain.FXQuote{}, err
+	}
+	if quote.From() != input.Amount.Currency() || quote.To() != to {
+		return domain.FXQuote{}, domain.ErrQuoteMismatch
+	}
+	return quote, nil
 }
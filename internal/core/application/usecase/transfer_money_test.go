@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/fx"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func (fixture *transferFixture) bookTransfer() *TransferMoneyUseCase {
+	return NewTransferMoneyUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.ledger, fixture.ledger,
+		fixture.accounts, memory.NewOutbox(), fx.NewFakeRateProvider(time.Minute),
+	)
+}
+
+func TestTransferMoneySettlesInBooks(t *testing.T) {
+	fixture := newTransferFixture(t)
+	ctx := context.Background()
+
+	// Alice has 600 available next to the 400 held by the outbound transfer.
+	output, err := fixture.bookTransfer().Execute(ctx, TransferInput{FromID: fixture.fromID, ToID: fixture.toID, Amount: mxn(600)})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if output.Status != "SETTLED" || output.Kind != "INTERNAL" {
+		t.Fatalf("book transfers settle at once, got %+v", output)
+	}
+	if from, _ := fixture.accounts.ByID(ctx, fixture.fromID); from.Balance().Amount() != 400 || from.AvailableBalance().Amount() != 0 {
+		t.Fatalf("from balance want=400/0 got=%s/%s", from.Balance(), from.AvailableBalance())
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, fixture.toID); balance != 600 {
+		t.Fatalf("ledger to balance want=600 got=%d", balance)
+	}
+	if _, err := fixture.bookTransfer().Execute(ctx, TransferInput{FromID: fixture.fromID, ToID: fixture.toID, Amount: mxn(1)}); !errors.Is(err, domain.ErrInsufficientFund) {
+		t.Fatalf("held funds cannot be transferred, got %v", err)
+	}
+}
+
+func TestTransferMoneyAcrossCurrencies(t *testing.T) {
+	fixture := newTransferFixture(t)
+	ctx := context.Background()
+	dave, err := NewOpenAccountUseCase(fixture.accounts).Execute(ctx, OpenAccountInput{HolderName: "Dave", CLABE: "072180000118359711", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("open dave: %v", err)
+	}
+
+	transfer := fixture.bookTransfer()
+	output, err := transfer.Execute(ctx, TransferInput{FromID: fixture.fromID, ToID: dave.ID, Amount: mxn(600)})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if output.CreditAmount != "0.35 USD" || output.FXRate != "0.058824" || output.FXQuoteID == "" {
+		t.Fatalf("unexpected conversion %+v", output)
+	}
+	if to, _ := fixture.accounts.ByID(ctx, dave.ID); to.Balance().String() != "0.35 USD" {
+		t.Fatalf("dave balance want 0.35 USD got %s", to.Balance())
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, dave.ID); balance != 35 {
+		t.Fatalf("ledger dave balance want=35 got=%d", balance)
+	}
+
+	usd, _ := domain.NewMoney(10, domain.USD)
+	_, err = transfer.Execute(ctx, TransferInput{FromID: dave.ID, ToID: fixture.fromID, Amount: usd, QuoteID: output.FXQuoteID})
+	if !errors.Is(err, domain.ErrQuoteMismatch) {
+		t.Fatalf("reusing an MXN->USD quote for USD->MXN: want ErrQuoteMismatch got %v", err)
+	}
+}
+
+func TestTransferMoneyRejectsFrozenDestination(t *testing.T) {
+	fixture := newTransferFixture(t)
+	if _, err := NewFreezeAccountUseCase(fixture.accounts, fixture.accounts).Execute(context.Background(), fixture.toID); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	_, err := fixture.bookTransfer().Execute(context.Background(), TransferInput{FromID: fixture.fromID, ToID: fixture.toID, Amount: mxn(100)})
+	if !errors.Is(err, domain.ErrAccountFrozen) {
+		t.Fatalf("want ErrAccountFrozen got %v", err)
+	}
+}
+
+func TestTransferAddressedByCLABE(t *testing.T) {
+	fixture := newTransferFixture(t)
+	transfer := fixture.bookTransfer()
+
+	output, err := transfer.Execute(context.Background(), TransferInput{FromCLABE: "032180000118359719", ToCLABE: "002180000118359710", Amount: mxn(100)})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if output.FromID != fixture.fromID || output.ToID != fixture.toID {
+		t.Fatalf("CLABEs resolved to %s -> %s", output.FromID, output.ToID)
+	}
+	_, err = transfer.Execute(context.Background(), TransferInput{FromID: fixture.fromID, ToCLABE: carolCLABE, Amount: mxn(100)})
+	if !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Fatalf("unknown CLABE: want ErrAccountNotFound got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+)
+
+// UnfreezeAccountUseCase lets a frozen account move money again.
+type UnfreezeAccountUseCase struct {
+	changer accountStatusChanger
+}
+
+func NewUnfreezeAccountUseCase(accountReader ports.AccountReader, accountWriter ports.AccountWriter) *UnfreezeAccountUseCase {
+	return &UnfreezeAccountUseCase{changer: accountStatusChanger{accountReader: accountReader, accountWriter: accountWriter}}
+}
+
+func (useCase *UnfreezeAccountUseCase) Execute(ctx context.Context, accountID string) (AccountStatusOutput, error) {
+	return useCase.changer.change(ctx, accountID, (*domain.Account).Unfreeze)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"time"
+)
+
+// TransferStatusInput is the rail's final word on an outbound transfer.
+type TransferStatusInput struct {
+	TrackingKey string
+	Status      domain.TransferStatus // SETTLED or RETURNED
+	Reason      string                // return cause, for RETURNED
+}
+
+// UpdateTransferStatusUseCase applies a status callback from the payment rail
+// to a SENT outbound transfer: SETTLED captures the hold, RETURNED gives the
+// money back to the sender (releasing the hold, or crediting the account if
+// the transfer had already settled). Money returned to an account closed since
+// is parked in the suspense account and recorded for manual review.
+// Callbacks may be delivered more than once; one for the status the transfer
+// already has changes nothing.
+type UpdateTransferStatusUseCase struct {
+	transferReader  ports.TransferReader
+	unitOfWork      ports.UnitOfWork
+	inconsistencies ports.InconsistencyRecorder
+	settlement      outboundSettlement
+}
+
+func NewUpdateTransferStatusUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	transferReader ports.TransferReader,
+	transferWriter ports.TransferWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+	outbox ports.Outbox,
+	inconsistencies ports.InconsistencyRecorder,
+) *UpdateTransferStatusUseCase {
+	return &UpdateTransferStatusUseCase{
+		transferReader:  transferReader,
+		unitOfWork:      unitOfWork,
+		inconsistencies: inconsistencies,
+		settlement: outboundSettlement{
+			accountReader:  accountReader,
+			accountWriter:  accountWriter,
+			transferWriter: transferWriter,
+			ledgerReader:   ledgerReader,
+			ledgerWriter:   ledgerWriter,
+			outbox:         outbox,
+		},
+	}
+}
+
+func (useCase *UpdateTransferStatusUseCase) Execute(ctx context.Context, input TransferStatusInput) (TransferOutput, error) {
+	if input.Status != domain.TransferSettled && input.Status != domain.TransferReturned {
+		return TransferOutput{}, fmt.Errorf("%w: rail cannot report %s", domain.ErrInvalidTransition, input.Status)
+	}
+	var updated *domain.Transfer
+	var suspended bool
+	err := retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		return useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			transfer, err := useCase.transferReader.TransferBySPEIKey(ctx, domain.OutboundSPEIKey(input.TrackingKey))
+			if err != nil {
+				return err
+			}
+			updated, suspended = transfer, false
+			switch {
+			case transfer.Status() == input.Status:
+				return nil // redelivered callback
+			case input.Status == domain.TransferSettled:
+				return useCase.settlement.settle(ctx, transfer, time.Now().UTC())
+			default:
+				suspended, err = useCase.settlement.returnFunds(ctx, transfer, input.Reason)
+				return err
+			}
+		})
+	})
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	if suspended {
+		err = useCase.inconsistencies.RecordInconsistency(ctx, ports.Inconsistency{
+			TransferID: updated.ID,
+			Reason:     "returned transfer cannot be refunded: account " + updated.FromID() + " is closed",
+			Resolution: "amount parked in " + ledger.SuspenseAccount + " until it is paid out to the holder",
+			DetectedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return TransferOutput{}, err
+		}
+	}
+	return newTransferOutput(updated), nil
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"testing"
+)
+
+// sentAsync processes the fixture's transfer through a rail that settles later.
+func (fixture *transferFixture) sentAsync(t *testing.T) (*UpdateTransferStatusUseCase, string) {
+	t.Helper()
+	if output := fixture.process(t, fixture.ledger, &stubGateway{sendStatus: ports.PaymentAccepted}); output.Sent != 1 {
+		t.Fatalf("want 1 sent got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferSent, 1000, 600)
+	transfer, _ := fixture.transfers.TransferByID(context.Background(), fixture.transferID)
+	useCase := NewUpdateTransferStatusUseCase(
+		fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+		fixture.ledger, fixture.ledger, fixture.accounts, memory.NewOutbox(), fixture.inconsistencies,
+	)
+	return useCase, transfer.TrackingKey()
+}
+
+func TestSettledCallbackCapturesHold(t *testing.T) {
+	fixture := newTransferFixture(t)
+	useCase, trackingKey := fixture.sentAsync(t)
+	ctx := context.Background()
+
+	for range 2 { // the redelivered callback changes nothing
+		if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferSettled}); err != nil {
+			t.Fatalf("settled: %v", err)
+		}
+		fixture.assertState(t, domain.TransferSettled, 600, 600)
+	}
+
+	// A settled transfer can still come back: the sender is credited again.
+	output, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta inexistente"})
+	if err != nil {
+		t.Fatalf("returned: %v", err)
+	}
+	if output.FailureReason != "cuenta inexistente" {
+		t.Fatalf("want return cause got %+v", output)
+	}
+	fixture.assertState(t, domain.TransferReturned, 1000, 1000)
+	if balance, _ := fixture.ledger.BalanceOf(ctx, ledger.SPEIClearingAccount); balance != 0 {
+		t.Fatalf("SPEI clearing balance want=0 got=%d", balance)
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, fixture.fromID); balance != 1000 {
+		t.Fatalf("ledger from balance want=1000 got=%d", balance)
+	}
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferSettled}); !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Fatalf("RETURNED is final, got %v", err)
+	}
+}
+
+func TestReturnedCallbackReleasesHold(t *testing.T) {
+	fixture := newTransferFixture(t)
+	useCase, trackingKey := fixture.sentAsync(t)
+	ctx := context.Background()
+
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta bloqueada"}); err != nil {
+		t.Fatalf("returned: %v", err)
+	}
+	fixture.assertState(t, domain.TransferReturned, 1000, 1000)
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: "unknown", Status: domain.TransferSettled}); !errors.Is(err, domain.ErrTransferNotFound) {
+		t.Fatalf("want ErrTransferNotFound got %v", err)
+	}
+}
+
+func TestReturnedCallbackRefundsFrozenAccount(t *testing.T) {
+	fixture := newTransferFixture(t)
+	useCase, trackingKey := fixture.sentAsync(t)
+	ctx := context.Background()
+
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferSettled}); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+	if _, err := NewFreezeAccountUseCase(fixture.accounts, fixture.accounts).Execute(ctx, fixture.fromID); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta inexistente"}); err != nil {
+		t.Fatalf("returned to a frozen account: %v", err)
+	}
+	fixture.assertState(t, domain.TransferReturned, 1000, 1000)
+	if balance, _ := fixture.ledger.BalanceOf(ctx, ledger.SPEIClearingAccount); balance != 0 {
+		t.Fatalf("nothing may be stranded in SPEI clearing, balance=%d", balance)
+	}
+	if from, _ := fixture.accounts.ByID(ctx, fixture.fromID); from.Status() != domain.AccountFrozen {
+		t.Fatalf("the refund must not unfreeze the account, got %s", from.Status())
+	}
+}
+
+func TestReturnedCallbackParksRefundForClosedAccount(t *testing.T) {
+	fixture := newTransferFixture(t)
+	useCase, trackingKey := fixture.sentAsync(t)
+	ctx := context.Background()
+
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferSettled}); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+	withdraw := NewWithdrawMoneyUseCase(fixture.accounts, fixture.accounts, fixture.ledger, fixture.ledger, fixture.accounts)
+	if _, err := withdraw.Execute(ctx, WithdrawInput{AccountID: fixture.fromID, Amount: mxn(600), Channel: "branch"}); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if _, err := NewCloseAccountUseCase(fixture.accounts, fixture.accounts).Execute(ctx, fixture.fromID); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta inexistente"}); err != nil {
+		t.Fatalf("returned to a closed account: %v", err)
+	}
+	fixture.assertState(t, domain.TransferReturned, 0, 0)
+	if balance, _ := fixture.ledger.BalanceOf(ctx, ledger.SPEIClearingAccount); balance != 0 {
+		t.Fatalf("nothing may be stranded in SPEI clearing, balance=%d", balance)
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, ledger.SuspenseAccount); balance != 400 {
+		t.Fatalf("suspense balance want=400 got=%d", balance)
+	}
+	if recorded := fixture.inconsistencies.All(); len(recorded) != 1 || recorded[0].TransferID != fixture.transferID {
+		t.Fatalf("want one inconsistency for the transfer, got %+v", recorded)
+	}
+
+	// The redelivered callback parks nothing more.
+	if _, err := useCase.Execute(ctx, TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta inexistente"}); err != nil {
+		t.Fatalf("redelivered: %v", err)
+	}
+	if balance, _ := fixture.ledger.BalanceOf(ctx, ledger.SuspenseAccount); balance != 400 || len(fixture.inconsistencies.All()) != 1 {
+		t.Fatalf("redelivery must change nothing, suspense=%d inconsistencies=%d", balance, len(fixture.inconsistencies.All()))
+	}
+}
+
+// racingGateway delivers STP's status callback while the order is still in
+// flight, then answers with answer.
+type racingGateway struct {
+	stubGateway
+	callback func(trackingKey string)
+}
+
+func (gateway *racingGateway) SendTransfer(ctx context.Context, order ports.PaymentOrder) (ports.PaymentResult, error) {
+	gateway.callback(order.TrackingKey)
+	return gateway.stubGateway.SendTransfer(ctx, order)
+}
+
+func TestCallbackDuringSendIsNotOverwritten(t *testing.T) {
+	for _, answer := range []stubGateway{
+		{sendStatus: ports.PaymentSettled},
+		{sendStatus: ports.PaymentAccepted},
+		{sendErr: &ports.PaymentError{Code: ports.RejectRailUnavailable, Reason: "busy"}},
+		{sendErr: &ports.PaymentError{Code: ports.RejectInvalidAccount, Reason: "cuenta inexistente"}},
+	} {
+		fixture := newTransferFixture(t)
+		useCase := NewUpdateTransferStatusUseCase(
+			fixture.accounts, fixture.accounts, fixture.transfers, fixture.transfers,
+			fixture.ledger, fixture.ledger, fixture.accounts, memory.NewOutbox(), fixture.inconsistencies,
+		)
+		gateway := &racingGateway{stubGateway: answer, callback: func(trackingKey string) {
+			input := TransferStatusInput{TrackingKey: trackingKey, Status: domain.TransferReturned, Reason: "cuenta bloqueada"}
+			if _, err := useCase.Execute(context.Background(), input); err != nil {
+				t.Fatalf("returned: %v", err)
+			}
+		}}
+		fixture.process(t, fixture.ledger, gateway)
+
+		// The hold was released once, by the return.
+		fixture.assertState(t, domain.TransferReturned, 1000, 1000)
+		if gateway.reversals != 0 {
+			t.Fatalf("%+v: a returned transfer is not reversed", answer)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"hexagonal-bank/internal/core/application/ports"
+	"hexagonal-bank/internal/core/domain"
+	"hexagonal-bank/internal/core/domain/ledger"
+	"hexagonal-bank/internal/shared/id"
+	"time"
+)
+
+type WithdrawInput struct {
+	AccountID string
+	Amount    domain.Money
+	Channel   string // ATM, BRANCH or CARD
+}
+
+type WithdrawOutput struct {
+	ID           string `json:"id"`
+	EntryID      string `json:"entry_id"`
+	Channel      string `json:"channel"`
+	Amount       string `json:"amount"`
+	Balance      string `json:"balance"`
+	BalanceCents int64  `json:"balance_cents"`
+	Currency     string `json:"currency"`
+}
+
+// WithdrawMoneyUseCase pays cash out of an account through a channel,
+// enforcing the channel's per-operation limit.
+type WithdrawMoneyUseCase struct {
+	accountReader ports.AccountReader
+	accountWriter ports.AccountWriter
+	ledgerReader  ports.LedgerReader
+	ledgerWriter  ports.LedgerWriter
+	unitOfWork    ports.UnitOfWork
+}
+
+func NewWithdrawMoneyUseCase(
+	accountReader ports.AccountReader,
+	accountWriter ports.AccountWriter,
+	ledgerReader ports.LedgerReader,
+	ledgerWriter ports.LedgerWriter,
+	unitOfWork ports.UnitOfWork,
+) *WithdrawMoneyUseCase {
+	return &WithdrawMoneyUseCase{
+		accountReader: accountReader,
+		accountWriter: accountWriter,
+		ledgerReader:  ledgerReader,
+		ledgerWriter:  ledgerWriter,
+		unitOfWork:    unitOfWork,
+	}
+}
+
+// Execute debits the account, replaying the whole read-modify-write when a
+// concurrent writer got there first.
+func (useCase *WithdrawMoneyUseCase) Execute(ctx context.Context, input WithdrawInput) (WithdrawOutput, error) {
+	channel, err := domain.ParseWithdrawalChannel(input.Channel)
+	if err != nil {
+		return WithdrawOutput{}, err
+	}
+	if err := channel.CheckLimit(input.Amount); err != nil {
+		return WithdrawOutput{}, err
+	}
+	var output WithdrawOutput
+	err = retryOnConflict(ctx, defaultConflictAttempts, func() error {
+		var err error
+		output, err = useCase.withdraw(ctx, input, channel)
+		return err
+	})
+	return output, err
+}
+
+// withdraw reads, debits and writes the account inside one unit of work, so
+// the ledger it reconciles against cannot move underneath it.
+func (useCase *WithdrawMoneyUseCase) withdraw(ctx context.Context, input WithdrawInput, channel domain.WithdrawalChannel) (WithdrawOutput, error) {
+	var output WithdrawOutput
+	err := useCase.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		account, err := useCase.accountReader.ByID(ctx, input.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := account.Debit(input.Amount); err != nil {
+			return err
+		}
+
+		// The bank no longer owes the money: cash leaves the vault.
+		entry, err := ledger.NewEntry(id.New(), ledger.KindWithdrawal, time.Now().UTC(),
+			ledger.DebitLeg(account.ID, input.Amount),
+			ledger.CreditLeg(ledger.CashAccount, input.Amount),
+		)
+		if err != nil {
+			return err
+		}
+		if err := reconcileEntry(ctx, useCase.ledgerReader, entry, account); err != nil {
+			return err
+		}
+
+		// Account and journal are persisted together or not at all
+		if err := useCase.accountWriter.Save(ctx, account); err != nil {
+			return err
+		}
+		if err := useCase.ledgerWriter.Post(ctx, entry); err != nil {
+			return err
+		}
+		output = WithdrawOutput{
+			ID:           account.ID,
+			EntryID:      entry.ID,
+			Channel:      string(channel),
+			Amount:       input.Amount.String(),
+			Balance:      account.Balance().String(),
+			BalanceCents: account.Balance().Amount(),
+			Currency:     string(account.Currency()),
+		}
+		return nil
+	})
+	return output, err
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagonal-bank/internal/adapters/out/memory"
+	"hexagonal-bank/internal/core/domain"
+	"sync"
+	"testing"
+)
+
+func TestWithdrawMoney(t *testing.T) {
+	ctx := context.Background()
+	accounts, journal := memory.NewAccountRepo(), memory.NewLedgerRepo()
+	account, err := NewOpenAccountUseCase(accounts).Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	deposit := NewDepositMoneyUseCase(accounts, accounts, journal, journal, accounts)
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(2_000_000)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	withdraw := NewWithdrawMoneyUseCase(accounts, accounts, journal, journal, accounts)
+
+	output, err := withdraw.Execute(ctx, WithdrawInput{AccountID: account.ID, Amount: mxn(150_000), Channel: "atm"})
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if output.Channel != "ATM" || output.BalanceCents != 1_850_000 {
+		t.Fatalf("unexpected output %+v", output)
+	}
+	if balance, _ := journal.BalanceOf(ctx, account.ID); balance != 1_850_000 {
+		t.Fatalf("ledger balance want=1850000 got=%d", balance)
+	}
+
+	cases := []struct {
+		name    string
+		input   WithdrawInput
+		wantErr error
+	}{
+		{"over ATM limit", WithdrawInput{AccountID: account.ID, Amount: mxn(900_001), Channel: "ATM"}, domain.ErrWithdrawalLimitExceeded},
+		{"over card limit", WithdrawInput{AccountID: account.ID, Amount: mxn(3_000_001), Channel: "CARD"}, domain.ErrWithdrawalLimitExceeded},
+		{"branch within limit but not funded", WithdrawInput{AccountID: account.ID, Amount: mxn(1_850_001), Channel: "BRANCH"}, domain.ErrInsufficientFund},
+		{"unknown channel", WithdrawInput{AccountID: account.ID, Amount: mxn(100), Channel: "CHEQUE"}, domain.ErrUnknownWithdrawalChannel},
+		{"zero amount", WithdrawInput{AccountID: account.ID, Amount: mxn(0), Channel: "ATM"}, domain.ErrInvalidAmount},
+	}
+	for _, tc := range cases {
+		if _, err := withdraw.Execute(ctx, tc.input); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: want %v got %v", tc.name, tc.wantErr, err)
+		}
+	}
+	if stored, _ := accounts.ByID(ctx, account.ID); stored.Balance().Amount() != 1_850_000 {
+		t.Fatalf("rejected withdrawals must not move money, balance=%s", stored.Balance())
+	}
+}
+
+func TestConcurrentWithdrawalsAndDepositsAllSucceed(t *testing.T) {
+	ctx := context.Background()
+	accounts, journal := memory.NewAccountRepo(), memory.NewLedgerRepo()
+	account, err := NewOpenAccountUseCase(accounts).Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	deposit := NewDepositMoneyUseCase(accounts, accounts, slowLedgerReader{journal}, journal, accounts)
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(10_000)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	withdraw := NewWithdrawMoneyUseCase(accounts, accounts, slowLedgerReader{journal}, journal, accounts)
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for range workers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := withdraw.Execute(ctx, WithdrawInput{AccountID: account.ID, Amount: mxn(300), Channel: "ATM"})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: mxn(100)})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent withdrawal or deposit: %v", err)
+		}
+	}
+	if balance, _ := journal.BalanceOf(ctx, account.ID); balance != 8_000 {
+		t.Fatalf("ledger balance want=8000 got=%d", balance)
+	}
+	if stored, _ := accounts.ByID(ctx, account.ID); stored.Balance().Amount() != 8_000 {
+		t.Fatalf("balance want=8000 got=%s", stored.Balance())
+	}
+}
+
+func TestWithdrawMoneyAppliesLimitsInTheAccountCurrency(t *testing.T) {
+	ctx := context.Background()
+	accounts, journal := memory.NewAccountRepo(), memory.NewLedgerRepo()
+	account, err := NewOpenAccountUseCase(accounts).Execute(ctx, OpenAccountInput{HolderName: "Alice", CLABE: "032180000118359719", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	usd := func(cents int64) domain.Money {
+		money, _ := domain.NewMoney(cents, domain.USD)
+		return money
+	}
+	deposit := NewDepositMoneyUseCase(accounts, accounts, journal, journal, accounts)
+	if _, err := deposit.Execute(ctx, DepositInput{AccountID: account.ID, Amount: usd(2_000_000)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	withdraw := NewWithdrawMoneyUseCase(accounts, accounts, journal, journal, accounts)
+
+	if _, err := withdraw.Execute(ctx, WithdrawInput{AccountID: account.ID, Amount: usd(900_000), Channel: "ATM"}); !errors.Is(err, domain.ErrWithdrawalLimitExceeded) {
+		t.Fatalf("9,000 USD at an ATM: want ErrWithdrawalLimitExceeded got %v", err)
+	}
+	if output, err := withdraw.Execute(ctx, WithdrawInput{AccountID: account.ID, Amount: usd(50_000), Channel: "ATM"}); err != nil || output.BalanceCents != 1_950_000 {
+		t.Fatalf("500 USD at an ATM: %+v %v", output, err)
+	}
+}
@@ -5,58 +5,281 @@ import (
 	"strings"
 )
 
+// AccountStatus is the lifecycle state of an Account.
+//
+//	ACTIVE ◄──► FROZEN
+//	  │
+//	  └──► CLOSED (only at zero balance, terminal)
+type AccountStatus string
+
+const (
+	AccountActive AccountStatus = "ACTIVE"
+	AccountFrozen AccountStatus = "FROZEN"
+	AccountClosed AccountStatus = "CLOSED"
+)
+
 // Account is an Entity responsible for protecting its invariants.
 // Invariants:
+//  - only ACTIVE accounts are credited, debited or take new holds
+//    (refunds of returned payments are credited to FROZEN accounts too)
 //  - balance must never go below 0
+//  - holds never exceed the balance (available balance >= 0)
+//  - every amount credited, debited or held is in the account's currency
 //  - holderName must not be empty
 type Account struct {
-	ID         string
+	ID string
+	// Version is the persisted revision used for optimistic concurrency.
+	// Repositories bump it on every successful write; 0 means never stored.
+	Version    int64
 	holderName string
 	clabe      CLABE
-	balance    int64 // stored in cents
+	status     AccountStatus
+	balance    Money // ledger balance, in the account's currency
+	// holds reserves funds by hold ID. The map is copy-on-write:
+	// it is replaced, never mutated, so shallow copies of Account stay independent.
+	holds map[string]Money
 }
 
-// NewAccount constructs a valid Account with zero balance.
-func NewAccount(id string, holderName string, clabe CLABE) (*Account, error) {
+// NewAccount constructs a valid Account with zero balance in currency.
+func NewAccount(id string, holderName string, clabe CLABE, currency Currency) (*Account, error) {
 	if strings.TrimSpace(holderName) == "" {
 		return nil, ErrEmptyHolder
 	}
+	balance, err := NewMoney(0, currency)
+	if err != nil {
+		return nil, err
+	}
 	return &Account{
 		ID:         id,
 		holderName: holderName,
 		clabe:      clabe,
-		balance:    0,
+		status:     AccountActive,
+		balance:    balance,
 	}, nil
 }
 
-// Debit decreases the balance while ensuring it never goes negative.
-func (a *Account) Debit(cents int64) error {
-	if cents <= 0 {
-		return ErrInvalidAmount
+// RestoreAccount rebuilds a stored Account, checking the invariants again
+// so that corrupt rows are not loaded as valid accounts.
+func RestoreAccount(id string, version int64, holderName string, clabe CLABE, status AccountStatus, balance Money, holds map[string]Money) (*Account, error) {
+	account, err := NewAccount(id, holderName, clabe, balance.Currency())
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case AccountActive, AccountFrozen, AccountClosed:
+	default:
+		return nil, fmt.Errorf("restore account %s: unknown status %q", id, status)
+	}
+	if balance.Amount() < 0 {
+		return nil, fmt.Errorf("restore account %s: negative balance %s", id, balance)
+	}
+	account.Version, account.status, account.balance = version, status, balance
+	for holdID, amount := range holds {
+		if !amount.IsPositive() || !amount.SameCurrency(balance) {
+			return nil, fmt.Errorf("restore account %s: invalid hold %s of %s", id, holdID, amount)
+		}
+	}
+	account.holds = holds
+	if account.AvailableBalance().Amount() < 0 {
+		return nil, fmt.Errorf("restore account %s: holds of %s exceed the balance %s", id, account.HeldBalance(), balance)
+	}
+	account.holds = account.copyHolds() // not shared with the caller
+	return account, nil
+}
+
+// Freeze blocks every movement of money until the account is unfrozen.
+// Holds already placed can still be captured or released.
+func (a *Account) Freeze() error {
+	return a.transition(AccountFrozen, AccountActive)
+}
+
+// Unfreeze reactivates a frozen account.
+func (a *Account) Unfreeze() error {
+	return a.transition(AccountActive, AccountFrozen)
+}
+
+// Close ends the account for good; it must hold no money and no holds.
+func (a *Account) Close() error {
+	if !a.balance.IsZero() || len(a.holds) > 0 {
+		return fmt.Errorf("%w: balance=%s held=%s", ErrAccountNotEmpty, a.balance, a.HeldBalance())
+	}
+	return a.transition(AccountClosed, AccountActive)
+}
+
+func (a *Account) transition(to AccountStatus, allowedFrom AccountStatus) error {
+	if a.status != allowedFrom {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidAccountTransition, a.status, to)
+	}
+	a.status = to
+	return nil
+}
+
+// EnsureActive reports why money cannot move through the account, if it cannot.
+func (a *Account) EnsureActive() error {
+	switch a.status {
+	case AccountFrozen:
+		return ErrAccountFrozen
+	case AccountClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// Debit decreases the balance while ensuring it never goes below what is held.
+func (a *Account) Debit(amount Money) error {
+	if err := a.checkAmount(amount); err != nil {
+		return err
 	}
-	if a.balance-cents < 0 {
+	if a.AvailableBalance().Amount() < amount.Amount() {
 		return ErrInsufficientFund
 	}
-	a.balance -= cents
+	balance, err := a.balance.Sub(amount)
+	if err != nil {
+		return err
+	}
+	a.balance = balance
 	return nil
 }
 
-// Credit increases the balance, validating amount.
-func (a *Account) Credit(cents int64) error {
-	if cents <= 0 {
+// Credit increases the balance, validating amount and guarding against overflow.
+func (a *Account) Credit(amount Money) error {
+	if err := a.checkAmount(amount); err != nil {
+		return err
+	}
+	balance, err := a.balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	a.balance = balance
+	return nil
+}
+
+// Refund credits money the account had sent and that came back (e.g. a
+// returned SPEI transfer). The holder still owns it, so unlike Credit it is
+// accepted while FROZEN. A CLOSED account must stay at zero: the caller has to
+// park the money elsewhere.
+func (a *Account) Refund(amount Money) error {
+	if a.status == AccountClosed {
+		return ErrAccountClosed
+	}
+	if err := a.checkMoney(amount); err != nil {
+		return err
+	}
+	balance, err := a.balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	a.balance = balance
+	return nil
+}
+
+// checkAmount enforces an active account and a positive amount in its currency.
+func (a *Account) checkAmount(amount Money) error {
+	if err := a.EnsureActive(); err != nil {
+		return err
+	}
+	return a.checkMoney(amount)
+}
+
+// checkMoney enforces a positive amount in the account's currency.
+func (a *Account) checkMoney(amount Money) error {
+	if !amount.IsPositive() {
 		return ErrInvalidAmount
 	}
-	a.balance += cents
+	if !amount.SameCurrency(a.balance) {
+		return fmt.Errorf("%w: account in %s, amount in %s", ErrCurrencyMismatch, a.balance.Currency(), amount.Currency())
+	}
+	return nil
+}
+
+// PlaceHold reserves amount of the available balance under holdID.
+func (a *Account) PlaceHold(holdID string, amount Money) error {
+	if err := a.checkAmount(amount); err != nil {
+		return err
+	}
+	if _, exists := a.holds[holdID]; exists {
+		return ErrHoldExists
+	}
+	if a.AvailableBalance().Amount() < amount.Amount() {
+		return ErrInsufficientFund
+	}
+	holds := a.copyHolds()
+	holds[holdID] = amount
+	a.holds = holds
 	return nil
 }
 
-func (a *Account) HolderName() string { return a.holderName }
-func (a *Account) CLABE() string      { return a.clabe.String() }
-func (a *Account) Balance() int64     { return a.balance }
+// CaptureHold turns a hold into a debit of the reserved amount.
+func (a *Account) CaptureHold(holdID string) (Money, error) {
+	amount, exists := a.holds[holdID]
+	if !exists {
+		return Money{}, ErrHoldNotFound
+	}
+	balance, err := a.balance.Sub(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	a.balance = balance
+	a.dropHold(holdID)
+	return amount, nil
+}
+
+// ReleaseHold gives reserved funds back to the available balance.
+func (a *Account) ReleaseHold(holdID string) error {
+	if _, exists := a.holds[holdID]; !exists {
+		return ErrHoldNotFound
+	}
+	a.dropHold(holdID)
+	return nil
+}
+
+func (a *Account) dropHold(holdID string) {
+	holds := a.copyHolds()
+	delete(holds, holdID)
+	a.holds = holds
+}
+
+func (a *Account) copyHolds() map[string]Money {
+	holds := make(map[string]Money, len(a.holds)+1)
+	for holdID, amount := range a.holds {
+		holds[holdID] = amount
+	}
+	return holds
+}
+
+// Holds returns a copy of the active holds by hold ID.
+func (a *Account) Holds() map[string]Money {
+	holds := make(map[string]Money, len(a.holds))
+	for holdID, amount := range a.holds {
+		holds[holdID] = amount
+	}
+	return holds
+}
+
+// HeldBalance is the sum of all active holds (never more than the balance).
+func (a *Account) HeldBalance() Money {
+	var held int64
+	for _, amount := range a.holds {
+		held += amount.Amount()
+	}
+	return Money{amount: held, currency: a.balance.Currency()}
+}
+
+// AvailableBalance is what can still be debited or held.
+func (a *Account) AvailableBalance() Money {
+	return Money{amount: a.balance.Amount() - a.HeldBalance().Amount(), currency: a.balance.Currency()}
+}
+
+func (a *Account) HolderName() string    { return a.holderName }
+func (a *Account) CLABE() string         { return a.clabe.String() }
+func (a *Account) BankAccount() CLABE    { return a.clabe }
+func (a *Account) Balance() Money        { return a.balance }
+func (a *Account) Currency() Currency    { return a.balance.Currency() }
+func (a *Account) Status() AccountStatus { return a.status }
 
 func (a *Account) String() string {
 	return fmt.Sprintf(
-		"Account{ID=%s, holder=%s, clabe=%s, balance=%d}",
-		a.ID, a.holderName, a.clabe.String(), a.balance,
+		"Account{ID=%s, version=%d, status=%s, holder=%s, clabe=%s, balance=%s, held=%s}",
+		a.ID, a.Version, a.status, a.holderName, a.clabe.String(), a.balance, a.HeldBalance(),
 	)
 }
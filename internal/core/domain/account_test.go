@@ -1,19 +1,96 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestAccountInvariants(t *testing.T) {
 	clabe, err := NewCLABE("032180000118359719")
 	if err != nil { t.Fatalf("clabe: %v", err) }
 
-	account, err := NewAccount("acc-1", "Alice", clabe)
+	account, err := NewAccount("acc-1", "Alice", clabe, MXN)
 	if err != nil { t.Fatalf("new account: %v", err) }
 
-	if err := account.Credit(1000); err != nil { t.Fatalf("credit: %v", err) }
-	if got := account.Balance(); got != 1000 { t.Fatalf("want=1000 got=%d", got) }
+	if err := account.Credit(mxn(1000)); err != nil { t.Fatalf("credit: %v", err) }
+	if got := account.Balance().Amount(); got != 1000 { t.Fatalf("want=1000 got=%d", got) }
 
-	if err := account.Debit(500); err != nil { t.Fatalf("debit: %v", err) }
-	if got := account.Balance(); got != 500 { t.Fatalf("want=500 got=%d", got) }
+	if err := account.Debit(mxn(500)); err != nil { t.Fatalf("debit: %v", err) }
+	if got := account.Balance().Amount(); got != 500 { t.Fatalf("want=500 got=%d", got) }
 
-	if err := account.Debit(600); err == nil { t.Fatalf("expected insufficient funds") }
+	if err := account.Debit(mxn(600)); err == nil { t.Fatalf("expected insufficient funds") }
+}
+
+func TestAccountHolds(t *testing.T) {
+	clabe, _ := NewCLABE("032180000118359719")
+	account, _ := NewAccount("acc-1", "Alice", clabe, MXN)
+	if err := account.Credit(mxn(1000)); err != nil { t.Fatalf("credit: %v", err) }
+
+	if err := account.PlaceHold("t-1", mxn(700)); err != nil { t.Fatalf("hold: %v", err) }
+	if got := account.AvailableBalance().Amount(); got != 300 { t.Fatalf("available want=300 got=%d", got) }
+	if got := account.Balance().Amount(); got != 1000 { t.Fatalf("ledger want=1000 got=%d", got) }
+
+	// Held funds cannot be spent twice.
+	if err := account.Debit(mxn(400)); err == nil { t.Fatalf("expected insufficient funds") }
+	if err := account.PlaceHold("t-2", mxn(400)); err == nil { t.Fatalf("expected insufficient funds") }
+
+	// Copies do not share holds.
+	snapshot := *account
+	if err := account.ReleaseHold("t-1"); err != nil { t.Fatalf("release: %v", err) }
+	if got := snapshot.AvailableBalance().Amount(); got != 300 { t.Fatalf("snapshot available want=300 got=%d", got) }
+
+	if err := account.PlaceHold("t-3", mxn(600)); err != nil { t.Fatalf("hold: %v", err) }
+	if _, err := account.CaptureHold("t-3"); err != nil { t.Fatalf("capture: %v", err) }
+	if got := account.Balance().Amount(); got != 400 { t.Fatalf("ledger want=400 got=%d", got) }
+	if _, err := account.CaptureHold("t-3"); err == nil { t.Fatalf("expected hold not found") }
+}
+
+func mxn(cents int64) Money {
+	money, _ := NewMoney(cents, MXN)
+	return money
+}
+
+func TestAccountRejectsOtherCurrencies(t *testing.T) {
+	clabe, _ := NewCLABE("032180000118359719")
+	account, _ := NewAccount("acc-1", "Alice", clabe, MXN)
+	dollars, _ := NewMoney(100, USD)
+	if err := account.Credit(dollars); !errors.Is(err, ErrCurrencyMismatch) { t.Fatalf("want currency mismatch got %v", err) }
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	clabe, _ := NewCLABE("032180000118359719")
+	account, _ := NewAccount("acc-1", "Alice", clabe, MXN)
+	if account.Status() != AccountActive { t.Fatalf("new accounts are active, got %s", account.Status()) }
+	if err := account.Credit(mxn(1000)); err != nil { t.Fatalf("credit: %v", err) }
+	if err := account.PlaceHold("t-1", mxn(300)); err != nil { t.Fatalf("hold: %v", err) }
+
+	if err := account.Freeze(); err != nil { t.Fatalf("freeze: %v", err) }
+	if err := account.Credit(mxn(1)); !errors.Is(err, ErrAccountFrozen) { t.Fatalf("credit: want frozen got %v", err) }
+	if err := account.Debit(mxn(1)); !errors.Is(err, ErrAccountFrozen) { t.Fatalf("debit: want frozen got %v", err) }
+	if err := account.PlaceHold("t-2", mxn(1)); !errors.Is(err, ErrAccountFrozen) { t.Fatalf("hold: want frozen got %v", err) }
+	// In-flight holds still settle or unwind.
+	if _, err := account.CaptureHold("t-1"); err != nil { t.Fatalf("capture while frozen: %v", err) }
+	if err := account.Freeze(); !errors.Is(err, ErrInvalidAccountTransition) { t.Fatalf("want invalid transition got %v", err) }
+	if err := account.Close(); !errors.Is(err, ErrAccountNotEmpty) { t.Fatalf("want not empty got %v", err) }
+
+	if err := account.Unfreeze(); err != nil { t.Fatalf("unfreeze: %v", err) }
+	if err := account.Debit(mxn(700)); err != nil { t.Fatalf("debit: %v", err) }
+	if err := account.Close(); err != nil { t.Fatalf("close: %v", err) }
+	if err := account.Credit(mxn(1)); !errors.Is(err, ErrAccountClosed) { t.Fatalf("credit: want closed got %v", err) }
+	// A closed account stays at zero, even for money coming back.
+	if err := account.Refund(mxn(5)); !errors.Is(err, ErrAccountClosed) || !account.Balance().IsZero() { t.Fatalf("refund: want closed got %v %s", err, account.Balance()) }
+	if err := account.Unfreeze(); !errors.Is(err, ErrInvalidAccountTransition) { t.Fatalf("want invalid transition got %v", err) }
+}
+
+func TestRestoreAccount(t *testing.T) {
+	clabe, _ := NewCLABE("032180000118359719")
+	holds := map[string]Money{"t-1": mxn(300)}
+	account, err := RestoreAccount("acc-1", 7, "Alice", clabe, AccountFrozen, mxn(1000), holds)
+	if err != nil { t.Fatalf("restore: %v", err) }
+	if account.Version != 7 || account.Status() != AccountFrozen || account.AvailableBalance().Amount() != 700 { t.Fatalf("restored %s", account) }
+	holds["t-2"] = mxn(1)
+	if len(account.Holds()) != 1 { t.Fatalf("holds must not be shared with the caller, got %v", account.Holds()) }
+
+	if _, err := RestoreAccount("acc-1", 7, "Alice", clabe, AccountActive, mxn(100), map[string]Money{"t-1": mxn(300)}); err == nil { t.Fatal("holds above the balance must be refused") }
+	if _, err := RestoreAccount("acc-1", 7, "Alice", clabe, "GONE", mxn(100), nil); err == nil { t.Fatal("unknown status must be refused") }
 }
@@ -1,12 +1,72 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Domain-level errors (business significance).
 // These are mapped at adapters-in (HTTP) to proper status codes.
 var (
-	ErrInvalidAmount    = errors.New("invalid amount: must be > 0")
-	ErrInsufficientFund = errors.New("insufficient funds")
-	ErrInvalidCLABE     = errors.New("invalid CLABE: must be 18 digits")
-	ErrEmptyHolder      = errors.New("holder name cannot be empty")
-)
\ No newline at end of file
+	ErrInvalidAmount            = errors.New("invalid amount: must be > 0")
+	ErrInsufficientFund         = errors.New("insufficient funds")
+	ErrInvalidCLABE             = errors.New("invalid CLABE: must be 18 digits")
+	ErrInvalidCLABEControlDigit = errors.New("invalid CLABE: control digit does not match")
+	ErrUnknownBank              = errors.New("invalid CLABE: unknown bank code")
+	ErrEmptyHolder              = errors.New("holder name cannot be empty")
+	ErrAccountNotFound          = errors.New("account not found")
+	ErrDuplicateCLABE           = errors.New("an account with this CLABE already exists")
+	ErrAccountFrozen            = errors.New("account is frozen")
+	ErrAccountClosed            = errors.New("account is closed")
+	ErrAccountNotEmpty          = errors.New("account must have zero balance and no holds to close")
+	ErrInvalidAccountTransition = errors.New("invalid account status transition")
+	ErrVersionConflict          = errors.New("account was modified concurrently")
+	ErrSameAccount              = errors.New("source and destination accounts must differ")
+	ErrInvalidBeneficiary       = errors.New("invalid beneficiary")
+	ErrInvalidPaymentDetails    = errors.New("invalid payment details")
+	ErrLocalBeneficiary         = errors.New("beneficiary CLABE belongs to a local account: use a book transfer")
+	ErrTransferNotFound         = errors.New("transfer not found")
+	ErrInvalidTransition        = errors.New("invalid transfer status transition")
+	ErrHoldExists               = errors.New("hold already exists")
+	ErrHoldNotFound             = errors.New("hold not found")
+	ErrUnsupportedCurrency      = errors.New("unsupported currency")
+	ErrCurrencyMismatch         = errors.New("currency mismatch")
+	ErrAmountOverflow           = errors.New("amount overflow")
+	ErrInvalidQuote             = errors.New("invalid fx quote")
+	ErrQuoteExpired             = errors.New("fx quote expired")
+	ErrQuoteNotFound            = errors.New("fx quote not found")
+	ErrQuoteMismatch            = errors.New("fx quote does not match the transfer currencies")
+	ErrInvalidMoney             = errors.New("invalid money: want e.g. \"125.50 MXN\"")
+	ErrUnknownWithdrawalChannel = errors.New("unknown withdrawal channel: want ATM, BRANCH or CARD")
+	ErrWithdrawalLimitExceeded  = errors.New("withdrawal limit exceeded")
+)
+
+// VersionConflictError reports a write based on a stale Account version.
+// It matches ErrVersionConflict with errors.Is.
+type VersionConflictError struct {
+	AccountID       string
+	ExpectedVersion int64 // version the writer read
+	ActualVersion   int64 // version currently stored
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s: account=%s expected version=%d actual=%d",
+		ErrVersionConflict, e.AccountID, e.ExpectedVersion, e.ActualVersion)
+}
+
+func (e *VersionConflictError) Is(target error) bool { return target == ErrVersionConflict }
+
+// TransferVersionConflictError reports a write based on a stale Transfer
+// version. It matches ErrVersionConflict with errors.Is.
+type TransferVersionConflictError struct {
+	TransferID      string
+	ExpectedVersion int64 // version the writer read
+	ActualVersion   int64 // version currently stored
+}
+
+func (e *TransferVersionConflictError) Error() string {
+	return fmt.Sprintf("transfer was modified concurrently: transfer=%s expected version=%d actual=%d",
+		e.TransferID, e.ExpectedVersion, e.ActualVersion)
+}
+
+func (e *TransferVersionConflictError) Is(target error) bool { return target == ErrVersionConflict }
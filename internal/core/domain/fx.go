@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// FXRateScale is the fixed-point scale of FXQuote rates (6 decimals).
+const FXRateScale = 1_000_000
+
+// FXQuote is a Value Object: a rate to convert From into To, valid until ExpiresAt.
+// Rate is fixed-point: 1 unit of From = rateMicros / FXRateScale units of To.
+type FXQuote struct {
+	id         string
+	from       Currency
+	to         Currency
+	rateMicros int64
+	expiresAt  time.Time
+}
+
+// NewFXQuote validates and builds a quote.
+func NewFXQuote(id string, from, to Currency, rateMicros int64, expiresAt time.Time) (FXQuote, error) {
+	if _, supported := minorUnits[from]; !supported {
+		return FXQuote{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, from)
+	}
+	if _, supported := minorUnits[to]; !supported {
+		return FXQuote{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, to)
+	}
+	if from == to || rateMicros <= 0 {
+		return FXQuote{}, ErrInvalidQuote
+	}
+	return FXQuote{id: id, from: from, to: to, rateMicros: rateMicros, expiresAt: expiresAt}, nil
+}
+
+func (q FXQuote) ID() string           { return q.id }
+func (q FXQuote) From() Currency       { return q.from }
+func (q FXQuote) To() Currency         { return q.to }
+func (q FXQuote) RateMicros() int64    { return q.rateMicros }
+func (q FXQuote) ExpiresAt() time.Time { return q.expiresAt }
+
+// Rate formats the rate as a decimal string, e.g. "17.250000".
+func (q FXQuote) Rate() string {
+	return strconv.FormatInt(q.rateMicros/FXRateScale, 10) + "." + fmt.Sprintf("%06d", q.rateMicros%FXRateScale)
+}
+
+// Expired reports whether the quote can no longer be used at now.
+func (q FXQuote) Expired(now time.Time) bool { return !now.Before(q.expiresAt) }
+
+// Convert turns amount (in From) into To at the quoted rate, rounding half up
+// to the target currency's minor unit. Expired quotes are rejected.
+func (q FXQuote) Convert(amount Money, now time.Time) (Money, error) {
+	if amount.Currency() != q.from {
+		return Money{}, fmt.Errorf("%w: quote is for %s, amount in %s", ErrCurrencyMismatch, q.from, amount.Currency())
+	}
+	if q.Expired(now) {
+		return Money{}, ErrQuoteExpired
+	}
+	// amount * rate / scale, adjusted for different minor units, in big ints.
+	numerator := new(big.Int).Mul(big.NewInt(amount.Amount()), big.NewInt(q.rateMicros))
+	denominator := big.NewInt(FXRateScale)
+	unitShift := q.to.MinorUnits() - q.from.MinorUnits()
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(unitShift))), nil)
+	if unitShift > 0 {
+		numerator.Mul(numerator, power)
+	} else {
+		denominator.Mul(denominator, power)
+	}
+	// round half up: (2n + d) / 2d
+	numerator.Mul(numerator, big.NewInt(2)).Add(numerator, denominator)
+	converted := numerator.Quo(numerator, denominator.Mul(denominator, big.NewInt(2)))
+	if !converted.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(converted.Int64(), q.to)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFXQuoteConvert(t *testing.T) {
+	now := time.Now()
+	quote, err := NewFXQuote("q-1", USD, MXN, 17_250_000, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Rate() != "17.250000" {
+		t.Fatalf("rate want 17.250000 got %s", quote.Rate())
+	}
+	usd, _ := NewMoney(10_000, USD) // 100.00 USD
+	converted, err := quote.Convert(usd, now)
+	if err != nil || converted.String() != "1725.00 MXN" {
+		t.Fatalf("want 1725.00 MXN got %s (%v)", converted, err)
+	}
+
+	// 0.01 MXN * 0.058824 = 0.00058824 USD rounds down, 0.09 MXN rounds up to 0.01.
+	back, _ := NewFXQuote("q-2", MXN, USD, 58_824, now.Add(time.Minute))
+	if converted, _ := back.Convert(mxn(1), now); converted.Amount() != 0 {
+		t.Fatalf("want 0 got %s", converted)
+	}
+	if converted, _ := back.Convert(mxn(9), now); converted.Amount() != 1 {
+		t.Fatalf("want 0.01 USD got %s", converted)
+	}
+
+	if _, err := quote.Convert(mxn(100), now); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("want ErrCurrencyMismatch got %v", err)
+	}
+	if _, err := quote.Convert(usd, now.Add(time.Minute)); !errors.Is(err, ErrQuoteExpired) {
+		t.Fatalf("want ErrQuoteExpired got %v", err)
+	}
+	if _, err := NewFXQuote("q-3", MXN, MXN, 1, now); !errors.Is(err, ErrInvalidQuote) {
+		t.Fatalf("want ErrInvalidQuote got %v", err)
+	}
+}
+
+func TestTransferApplyQuote(t *testing.T) {
+	now := time.Now()
+	quote, _ := NewFXQuote("q-1", MXN, USD, 58_824, now.Add(time.Minute))
+	transfer, _ := NewTransfer("t-1", "a", "b", mxn(100_000), now)
+	if err := transfer.ApplyQuote(quote, now); err != nil {
+		t.Fatal(err)
+	}
+	if transfer.Amount().String() != "1000.00 MXN" || transfer.CreditAmount().String() != "58.82 USD" {
+		t.Fatalf("got debit=%s credit=%s", transfer.Amount(), transfer.CreditAmount())
+	}
+	if applied, converted := transfer.Quote(); !converted || applied.ID() != "q-1" {
+		t.Fatalf("want quote q-1 recorded")
+	}
+
+	tiny, _ := NewTransfer("t-2", "a", "b", mxn(1), now)
+	if err := tiny.ApplyQuote(quote, now); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("amount converting to zero: want ErrInvalidAmount got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/core/domain"
+	"time"
+)
+
+// Ledger-level errors (business significance).
+var (
+	ErrUnbalancedEntry  = errors.New("unbalanced journal entry: legs must sum to zero per currency")
+	ErrTooFewLegs       = errors.New("journal entry needs at least two legs")
+	ErrZeroLeg          = errors.New("journal leg amount cannot be zero")
+	ErrBalanceMismatch  = errors.New("account balance does not match ledger")
+	ErrMissingAccountID = errors.New("journal leg needs an account id")
+)
+
+// System accounts represent the bank's side of each entry.
+// Customer accounts are identified by their Account.ID.
+const (
+	// CashAccount is the settlement account money enters/leaves through on deposits.
+	CashAccount = "system:cash"
+	// FXPositionAccount absorbs both currencies of a conversion, keeping
+	// each currency balanced on its own.
+	FXPositionAccount = "system:fx"
+	// SPEIClearingAccount holds what we owe other banks for outbound SPEI
+	// transfers until the central bank settles them.
+	SPEIClearingAccount = "system:spei"
+	// SuspenseAccount holds money we cannot pay to its owner, e.g. a transfer
+	// returned to an account closed since, until someone resolves it.
+	SuspenseAccount = "system:suspense"
+)
+
+// Entry kinds describe the business operation that produced an entry.
+const (
+	KindDeposit    = "deposit"
+	KindWithdrawal = "withdrawal"
+	KindTransfer   = "transfer"
+)
+
+// Leg is one side of a journal entry.
+// Amount is signed from the account holder's point of view:
+// positive credits the account, negative debits it.
+type Leg struct {
+	AccountID string
+	Amount    int64 // minor units of Currency
+	Currency  domain.Currency
+}
+
+// CreditLeg increases the balance of accountID by amount.
+func CreditLeg(accountID string, amount domain.Money) Leg {
+	return Leg{AccountID: accountID, Amount: amount.Amount(), Currency: amount.Currency()}
+}
+
+// DebitLeg decreases the balance of accountID by amount.
+func DebitLeg(accountID string, amount domain.Money) Leg {
+	return Leg{AccountID: accountID, Amount: -amount.Amount(), Currency: amount.Currency()}
+}
+
+// Entry is an immutable, balanced journal entry.
+// Invariants:
+//   - it has at least two legs
+//   - no leg is zero
+//   - the legs sum to zero in every currency (double-entry)
+type Entry struct {
+	ID       string
+	Kind     string
+	PostedAt time.Time
+	Legs     []Leg
+}
+
+// NewEntry validates legs and builds a balanced Entry.
+func NewEntry(id, kind string, postedAt time.Time, legs ...Leg) (Entry, error) {
+	if len(legs) < 2 {
+		return Entry{}, ErrTooFewLegs
+	}
+	totals := make(map[domain.Currency]int64)
+	for _, leg := range legs {
+		if leg.AccountID == "" {
+			return Entry{}, ErrMissingAccountID
+		}
+		if leg.Amount == 0 {
+			return Entry{}, ErrZeroLeg
+		}
+		totals[leg.Currency] += leg.Amount
+	}
+	for _, total := range totals {
+		if total != 0 {
+			return Entry{}, ErrUnbalancedEntry
+		}
+	}
+	copied := make([]Leg, len(legs))
+	copy(copied, legs)
+	return Entry{ID: id, Kind: kind, PostedAt: postedAt, Legs: copied}, nil
+}
+
+// AmountFor returns the net effect of the entry on accountID, in minor units.
+// Customer accounts hold a single currency, so legs are summed as is.
+func (e Entry) AmountFor(accountID string) int64 {
+	var net int64
+	for _, leg := range e.Legs {
+		if leg.AccountID == accountID {
+			net += leg.Amount
+		}
+	}
+	return net
+}
+
+// Reconcile checks that a stored balance equals what the ledger says.
+func Reconcile(accountID string, balance, ledgerBalance int64) error {
+	if balance != ledgerBalance {
+		return fmt.Errorf("%w: account=%s balance=%d ledger=%d", ErrBalanceMismatch, accountID, balance, ledgerBalance)
+	}
+	return nil
+}
+
+// Line is an entry as it appears on one account's statement.
+type Line struct {
+	EntryID        string
+	Kind           string
+	PostedAt       time.Time
+	Counterparty   string // other account(s) involved, comma separated
+	Amount         int64  // signed effect on the account, in minor units
+	Currency       domain.Currency
+	RunningBalance int64 // account balance right after this entry
+}
+
+// Statement projects entries (given in posting order) onto accountID,
+// skipping entries that do not touch it.
+func Statement(accountID string, entries []Entry) []Line {
+	var lines []Line
+	var running int64
+	for _, entry := range entries {
+		amount := entry.AmountFor(accountID)
+		if amount == 0 {
+			continue
+		}
+		running += amount
+		lines = append(lines, Line{
+			EntryID:        entry.ID,
+			Kind:           entry.Kind,
+			PostedAt:       entry.PostedAt,
+			Counterparty:   entry.counterpartyOf(accountID),
+			Amount:         amount,
+			Currency:       entry.currencyOf(accountID),
+			RunningBalance: running,
+		})
+	}
+	return lines
+}
+
+func (e Entry) currencyOf(accountID string) domain.Currency {
+	for _, leg := range e.Legs {
+		if leg.AccountID == accountID {
+			return leg.Currency
+		}
+	}
+	return ""
+}
+
+func (e Entry) counterpartyOf(accountID string) string {
+	counterparty := ""
+	for _, leg := range e.Legs {
+		if leg.AccountID == accountID {
+			continue
+		}
+		if counterparty != "" {
+			counterparty += ","
+		}
+		counterparty += leg.AccountID
+	}
+	return counterparty
+}
@@ -0,0 +1,71 @@
+package ledger
+
+import (
+	"errors"
+	"hexagonal-bank/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func mxn(cents int64) domain.Money {
+	money, _ := domain.NewMoney(cents, domain.MXN)
+	return money
+}
+
+func TestEntryMustBalance(t *testing.T) {
+	now := time.Now()
+
+	entry, err := NewEntry("e-1", KindTransfer, now, DebitLeg("a", mxn(500)), CreditLeg("b", mxn(500)))
+	if err != nil {
+		t.Fatalf("balanced entry: %v", err)
+	}
+	if got := entry.AmountFor("a"); got != -500 {
+		t.Fatalf("want=-500 got=%d", got)
+	}
+	if got := entry.AmountFor("b"); got != 500 {
+		t.Fatalf("want=500 got=%d", got)
+	}
+
+	if _, err := NewEntry("e-2", KindTransfer, now, DebitLeg("a", mxn(500)), CreditLeg("b", mxn(400))); !errors.Is(err, ErrUnbalancedEntry) {
+		t.Fatalf("want ErrUnbalancedEntry got %v", err)
+	}
+	if _, err := NewEntry("e-3", KindDeposit, now, CreditLeg("b", mxn(400))); !errors.Is(err, ErrTooFewLegs) {
+		t.Fatalf("want ErrTooFewLegs got %v", err)
+	}
+	if _, err := NewEntry("e-4", KindDeposit, now, DebitLeg("a", mxn(0)), CreditLeg("b", mxn(0))); !errors.Is(err, ErrZeroLeg) {
+		t.Fatalf("want ErrZeroLeg got %v", err)
+	}
+
+	// Balanced in total but not per currency.
+	dollars, _ := domain.NewMoney(500, domain.USD)
+	if _, err := NewEntry("e-5", KindTransfer, now, DebitLeg("a", mxn(500)), CreditLeg("b", dollars)); !errors.Is(err, ErrUnbalancedEntry) {
+		t.Fatalf("want ErrUnbalancedEntry got %v", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	if err := Reconcile("a", 100, 100); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := Reconcile("a", 100, 90); !errors.Is(err, ErrBalanceMismatch) {
+		t.Fatalf("want ErrBalanceMismatch got %v", err)
+	}
+}
+
+func TestStatementRunningBalance(t *testing.T) {
+	now := time.Now()
+	deposit, _ := NewEntry("e-1", KindDeposit, now, DebitLeg(CashAccount, mxn(1000)), CreditLeg("a", mxn(1000)))
+	unrelated, _ := NewEntry("e-2", KindDeposit, now, DebitLeg(CashAccount, mxn(50)), CreditLeg("c", mxn(50)))
+	transfer, _ := NewEntry("e-3", KindTransfer, now, DebitLeg("a", mxn(300)), CreditLeg("b", mxn(300)))
+
+	lines := Statement("a", []Entry{deposit, unrelated, transfer})
+	if len(lines) != 2 {
+		t.Fatalf("want=2 lines got=%d", len(lines))
+	}
+	if lines[0].Counterparty != CashAccount || lines[0].RunningBalance != 1000 {
+		t.Fatalf("unexpected first line: %+v", lines[0])
+	}
+	if lines[1].Counterparty != "b" || lines[1].Amount != -300 || lines[1].RunningBalance != 700 {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+}
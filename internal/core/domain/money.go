@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	MXN Currency = "MXN"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+)
+
+// minorUnits lists supported currencies and their decimal places.
+var minorUnits = map[Currency]int{
+	MXN: 2,
+	USD: 2,
+	EUR: 2,
+}
+
+// ParseCurrency validates an ISO 4217 code against the supported set.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, supported := minorUnits[currency]; !supported {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+	}
+	return currency, nil
+}
+
+// MinorUnits is the number of decimal places of the currency (2 for MXN).
+func (c Currency) MinorUnits() int { return minorUnits[c] }
+
+// Money is a Value Object: an amount in minor units (cents) of a currency.
+// Arithmetic never mixes currencies and never overflows silently.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// NewMoney builds Money from minor units.
+func NewMoney(amount int64, currency Currency) (Money, error) {
+	if _, supported := minorUnits[currency]; !supported {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// ParseMoney reads a decimal amount followed by a currency code, e.g. "125.50 MXN".
+// It rejects more decimals than the currency allows.
+func ParseMoney(raw string) (Money, error) {
+	fields := strings.Fields(raw)
+	if len(fields) != 2 {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, raw)
+	}
+	currency, err := ParseCurrency(fields[1])
+	if err != nil {
+		return Money{}, err
+	}
+
+	decimal := fields[0]
+	negative := strings.HasPrefix(decimal, "-")
+	decimal = strings.TrimPrefix(decimal, "-")
+	whole, fraction, _ := strings.Cut(decimal, ".")
+	if whole == "" || len(fraction) > currency.MinorUnits() || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, raw)
+	}
+	fraction += strings.Repeat("0", currency.MinorUnits()-len(fraction))
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrAmountOverflow, raw)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Amount is the value in minor units (cents).
+func (m Money) Amount() int64                 { return m.amount }
+func (m Money) Currency() Currency            { return m.currency }
+func (m Money) IsPositive() bool              { return m.amount > 0 }
+func (m Money) IsZero() bool                  { return m.amount == 0 }
+func (m Money) Negate() Money                 { return Money{amount: -m.amount, currency: m.currency} }
+func (m Money) Zero() Money                   { return Money{currency: m.currency} }
+func (m Money) SameCurrency(other Money) bool { return m.currency == other.currency }
+
+// Add returns m + other.
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	if (other.amount > 0 && m.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && m.amount < math.MinInt64-other.amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(other.Negate())
+}
+
+// LessThan compares two amounts of the same currency.
+func (m Money) LessThan(other Money) (bool, error) {
+	if !m.SameCurrency(other) {
+		return false, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return m.amount < other.amount, nil
+}
+
+// String formats as "<decimal> <currency>", e.g. "125.50 MXN".
+func (m Money) String() string {
+	units := m.currency.MinorUnits()
+	magnitude := strconv.FormatUint(absAmount(m.amount), 10)
+	if units > 0 {
+		if len(magnitude) <= units {
+			magnitude = strings.Repeat("0", units-len(magnitude)+1) + magnitude
+		}
+		magnitude = magnitude[:len(magnitude)-units] + "." + magnitude[len(magnitude)-units:]
+	}
+	if m.amount < 0 {
+		magnitude = "-" + magnitude
+	}
+	return magnitude + " " + string(m.currency)
+}
+
+func absAmount(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1 // safe for math.MinInt64
+	}
+	return uint64(amount)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseAndFormatMoney(t *testing.T) {
+	cases := map[string]string{
+		"125.50 MXN": "125.50 MXN",
+		"125.5 mxn":  "125.50 MXN",
+		"125 USD":    "125.00 USD",
+		"0.07 EUR":   "0.07 EUR",
+		"-3.10 MXN":  "-3.10 MXN",
+	}
+	for raw, want := range cases {
+		money, err := ParseMoney(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		if got := money.String(); got != want {
+			t.Errorf("%s: want %s got %s", raw, want, got)
+		}
+	}
+
+	for _, raw := range []string{"125.505 MXN", "12,50 MXN", "MXN", "1.00", ".50 MXN"} {
+		if _, err := ParseMoney(raw); !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("%s: want ErrInvalidMoney got %v", raw, err)
+		}
+	}
+	if _, err := ParseMoney("1.00 XYZ"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("want ErrUnsupportedCurrency got %v", err)
+	}
+	if _, err := ParseMoney("99999999999999999999 MXN"); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("want ErrAmountOverflow got %v", err)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	pesos, _ := NewMoney(math.MaxInt64, MXN)
+	if _, err := pesos.Add(mxn(1)); !errors.Is(err, ErrAmountOverflow) {
+		t.Fatalf("want ErrAmountOverflow got %v", err)
+	}
+	dollars, _ := NewMoney(1, USD)
+	if _, err := mxn(1).Add(dollars); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("want ErrCurrencyMismatch got %v", err)
+	}
+	difference, err := mxn(500).Sub(mxn(200))
+	if err != nil || difference.Amount() != 300 {
+		t.Fatalf("want 300 got %v (%v)", difference, err)
+	}
+}
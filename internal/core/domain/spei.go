@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SPEI field limits (as accepted by STP).
+const (
+	maxBeneficiaryNameLength = 40
+	maxConceptLength         = 40
+	maxNumericReference      = 9_999_999 // 7 digits
+	maxTrackingKeyLength     = 30
+)
+
+// Beneficiary is a Value Object: who receives an outbound SPEI transfer.
+type Beneficiary struct {
+	clabe CLABE
+	name  string
+}
+
+// NewBeneficiary validates the destination CLABE (control digit and bank)
+// and the holder name the receiving bank will match against.
+func NewBeneficiary(clabe, name string) (Beneficiary, error) {
+	parsed, err := NewCLABE(clabe)
+	if err != nil {
+		return Beneficiary{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxBeneficiaryNameLength {
+		return Beneficiary{}, fmt.Errorf("%w: name must have 1 to %d characters", ErrInvalidBeneficiary, maxBeneficiaryNameLength)
+	}
+	return Beneficiary{clabe: parsed, name: name}, nil
+}
+
+func (b Beneficiary) CLABE() CLABE { return b.clabe }
+func (b Beneficiary) Name() string { return b.name }
+
+// Originator is who sent an inbound SPEI transfer, as reported by the
+// ordering bank. It is informative only, so it is kept as received.
+type Originator struct {
+	CLABE    string
+	Name     string
+	BankCode string // 3-digit institution code, e.g. "002"
+}
+
+// PaymentDetails are the free-form fields SPEI carries to the beneficiary.
+type PaymentDetails struct {
+	Concept   string // "concepto de pago", up to 40 characters
+	Reference int    // "referencia numérica", 1 to 7 digits
+}
+
+// Validate checks the details against SPEI limits.
+func (d PaymentDetails) Validate() error {
+	concept := strings.TrimSpace(d.Concept)
+	if concept == "" || utf8.RuneCountInString(concept) > maxConceptLength {
+		return fmt.Errorf("%w: concept must have 1 to %d characters", ErrInvalidPaymentDetails, maxConceptLength)
+	}
+	if d.Reference < 1 || d.Reference > maxNumericReference {
+		return fmt.Errorf("%w: reference must be a number from 1 to %d", ErrInvalidPaymentDetails, maxNumericReference)
+	}
+	return nil
+}
+
+// SPEIKey identifies a SPEI payment. A clave de rastreo is only unique per
+// sending institution and operation date, and the keys other banks send us
+// share a namespace with the ones we generate, so the direction is part of
+// the key too. Outbound keys are our own transfer IDs, unique on their own:
+// their bank code and operation date are left empty.
+type SPEIKey struct {
+	Kind          TransferKind
+	BankCode      string // sending institution (SPEI_IN)
+	TrackingKey   string
+	OperationDate time.Time // midnight UTC (SPEI_IN)
+}
+
+// InboundSPEIKey is the key of a payment another bank sent us.
+func InboundSPEIKey(bankCode, trackingKey string, operationDate time.Time) SPEIKey {
+	return SPEIKey{
+		Kind:          TransferInbound,
+		BankCode:      bankCode,
+		TrackingKey:   strings.TrimSpace(trackingKey),
+		OperationDate: operationDay(operationDate),
+	}
+}
+
+// OutboundSPEIKey is the key of a payment we sent.
+func OutboundSPEIKey(trackingKey string) SPEIKey {
+	return SPEIKey{Kind: TransferOutbound, TrackingKey: trackingKey}
+}
+
+// operationDay keeps only the calendar date, so keys compare with ==.
+func operationDay(date time.Time) time.Time {
+	if date.IsZero() {
+		return time.Time{}
+	}
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
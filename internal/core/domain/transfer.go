@@ -0,0 +1,440 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TransferKind tells book transfers between our own accounts from SPEI
+// transfers leaving or entering the bank.
+type TransferKind string
+
+const (
+	// TransferInternal moves money between two local accounts; it settles
+	// in our books alone and never touches the payment rail.
+	TransferInternal TransferKind = "INTERNAL"
+	// TransferOutbound sends money to an external CLABE through SPEI.
+	TransferOutbound TransferKind = "SPEI_OUT"
+	// TransferInbound credits a local account with money another bank sent
+	// through SPEI.
+	TransferInbound TransferKind = "SPEI_IN"
+)
+
+// TransferStatus is the lifecycle state of a Transfer.
+//
+//	INTERNAL, SPEI_IN:  PENDING ──► SETTLED
+//
+//	SPEI_OUT:  PENDING ◄─► SENT ──► SETTLED ──► REVERSED
+//	              │          │         │
+//	              │          ├─────────┴──► RETURNED
+//	              │          │
+//	              └──────────┴──► FAILED
+//
+// A SENT transfer goes back to PENDING when it never reached the rail or the
+// rail asks to try again later.
+type TransferStatus string
+
+const (
+	TransferPending  TransferStatus = "PENDING"
+	TransferSent     TransferStatus = "SENT"
+	TransferSettled  TransferStatus = "SETTLED"
+	TransferFailed   TransferStatus = "FAILED"
+	TransferReversed TransferStatus = "REVERSED"
+	// TransferReturned: the beneficiary's bank sent the money back
+	// ("devolución"), before or after the transfer settled.
+	TransferReturned TransferStatus = "RETURNED"
+)
+
+// RailRejection is the payment rail's refusal of an outbound transfer.
+type RailRejection struct {
+	Code      string // the rail's rejection code, e.g. "INVALID_ACCOUNT"
+	Retryable bool   // the same order may go through later
+	Reason    string // the rail's own description
+}
+
+// Transfer is an Entity tracking money moving from an account to another
+// local account (toID), to an external beneficiary, or into a local account
+// from an external originator.
+// Invariants:
+//   - amount must be > 0
+//   - source and destination must differ
+//   - outbound transfers carry a beneficiary and valid payment details
+//   - SPEI transfers carry a tracking key
+//   - status only moves along the lifecycle of its kind (above)
+type Transfer struct {
+	ID string
+	// Version is the persisted revision used for optimistic concurrency.
+	// Repositories bump it on every successful write; 0 means never stored.
+	Version       int64
+	kind          TransferKind
+	fromID        string
+	toID          string      // local destination (INTERNAL, SPEI_IN)
+	beneficiary   Beneficiary // external destination (SPEI_OUT)
+	originator    Originator  // external source (SPEI_IN)
+	trackingKey   string      // SPEI "clave de rastreo"
+	operationDate time.Time   // the rail's "fecha de operación" (SPEI_IN)
+	details       PaymentDetails
+	amount        Money    // debited from the source, in its currency
+	creditAmount  Money    // credited to the destination (differs when converted)
+	quote         *FXQuote // set for cross-currency transfers
+	status        TransferStatus
+	failureReason string
+	railReference string         // the rail's own order ID (SPEI_OUT)
+	rejection     *RailRejection // the rail's last refusal (SPEI_OUT)
+	attempts      int            // times handed to the rail (SPEI_OUT)
+	acceptedAt    time.Time      // when the rail took the order (SPEI_OUT)
+	settledAt     time.Time
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+// NewTransfer constructs a PENDING book transfer between two local accounts.
+func NewTransfer(id, fromID, toID string, amount Money, now time.Time) (*Transfer, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if fromID == toID {
+		return nil, ErrSameAccount
+	}
+	return &Transfer{
+		ID:           id,
+		kind:         TransferInternal,
+		fromID:       fromID,
+		toID:         toID,
+		amount:       amount,
+		creditAmount: amount,
+		status:       TransferPending,
+		createdAt:    now,
+		updatedAt:    now,
+	}, nil
+}
+
+// NewOutboundTransfer constructs a PENDING SPEI transfer to an external
+// beneficiary. SPEI settles in pesos only.
+func NewOutboundTransfer(id, fromID string, beneficiary Beneficiary, details PaymentDetails, amount Money, now time.Time) (*Transfer, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if amount.Currency() != MXN {
+		return nil, fmt.Errorf("%w: SPEI settles in MXN, amount in %s", ErrCurrencyMismatch, amount.Currency())
+	}
+	if beneficiary.Name() == "" {
+		return nil, ErrInvalidBeneficiary
+	}
+	if err := details.Validate(); err != nil {
+		return nil, err
+	}
+	details.Concept = strings.TrimSpace(details.Concept)
+	return &Transfer{
+		ID:           id,
+		kind:         TransferOutbound,
+		fromID:       fromID,
+		beneficiary:  beneficiary,
+		trackingKey:  id,
+		details:      details,
+		amount:       amount,
+		creditAmount: amount,
+		status:       TransferPending,
+		createdAt:    now,
+		updatedAt:    now,
+	}, nil
+}
+
+// NewInboundTransfer constructs a PENDING SPEI transfer received from another
+// bank for the local account toID. trackingKey is the sender's clave de
+// rastreo which, with the sending bank and operationDate, identifies the
+// payment across redeliveries (see SPEIKey).
+func NewInboundTransfer(id, trackingKey string, operationDate time.Time, toID string, originator Originator, details PaymentDetails, amount Money, now time.Time) (*Transfer, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if amount.Currency() != MXN {
+		return nil, fmt.Errorf("%w: SPEI settles in MXN, amount in %s", ErrCurrencyMismatch, amount.Currency())
+	}
+	trackingKey = strings.TrimSpace(trackingKey)
+	if trackingKey == "" || utf8.RuneCountInString(trackingKey) > maxTrackingKeyLength {
+		return nil, fmt.Errorf("%w: tracking key must have 1 to %d characters", ErrInvalidPaymentDetails, maxTrackingKeyLength)
+	}
+	if operationDate.IsZero() {
+		return nil, fmt.Errorf("%w: operation date is required", ErrInvalidPaymentDetails)
+	}
+	details.Concept = strings.TrimSpace(details.Concept)
+	return &Transfer{
+		ID:            id,
+		kind:          TransferInbound,
+		toID:          toID,
+		originator:    originator,
+		trackingKey:   trackingKey,
+		operationDate: operationDay(operationDate),
+		details:       details,
+		amount:        amount,
+		creditAmount:  amount,
+		status:        TransferPending,
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// TransferSnapshot is the stored form of a Transfer, for repositories that
+// cannot keep the Transfer itself.
+type TransferSnapshot struct {
+	ID            string
+	Version       int64
+	Kind          TransferKind
+	FromID        string
+	ToID          string
+	Beneficiary   Beneficiary
+	Originator    Originator
+	TrackingKey   string
+	OperationDate time.Time
+	Details       PaymentDetails
+	Amount        Money
+	CreditAmount  Money
+	Quote         *FXQuote
+	Status        TransferStatus
+	FailureReason string
+	RailReference string
+	Rejection     *RailRejection
+	Attempts      int
+	AcceptedAt    time.Time
+	SettledAt     time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Snapshot returns the transfer's stored form.
+func (t *Transfer) Snapshot() TransferSnapshot {
+	snapshot := TransferSnapshot{
+		ID: t.ID, Version: t.Version, Kind: t.kind, FromID: t.fromID, ToID: t.toID,
+		Beneficiary: t.beneficiary, Originator: t.originator, TrackingKey: t.trackingKey, OperationDate: t.operationDate,
+		Details: t.details, Amount: t.amount, CreditAmount: t.creditAmount,
+		Status: t.status, FailureReason: t.failureReason, RailReference: t.railReference, Attempts: t.attempts,
+		AcceptedAt: t.acceptedAt, SettledAt: t.settledAt, CreatedAt: t.createdAt, UpdatedAt: t.updatedAt,
+	}
+	if t.quote != nil {
+		quote := *t.quote
+		snapshot.Quote = &quote
+	}
+	if t.rejection != nil {
+		rejection := *t.rejection
+		snapshot.Rejection = &rejection
+	}
+	return snapshot
+}
+
+// RestoreTransfer rebuilds a stored Transfer, checking the invariants again
+// so that corrupt rows are not loaded as valid transfers.
+func RestoreTransfer(snapshot TransferSnapshot) (*Transfer, error) {
+	if !snapshot.Amount.IsPositive() || !snapshot.CreditAmount.IsPositive() {
+		return nil, fmt.Errorf("restore transfer %s: amounts must be positive", snapshot.ID)
+	}
+	switch snapshot.Status {
+	case TransferPending, TransferSent, TransferSettled, TransferFailed, TransferReversed, TransferReturned:
+	default:
+		return nil, fmt.Errorf("restore transfer %s: unknown status %q", snapshot.ID, snapshot.Status)
+	}
+	switch snapshot.Kind {
+	case TransferInternal:
+		if snapshot.FromID == snapshot.ToID {
+			return nil, fmt.Errorf("restore transfer %s: %w", snapshot.ID, ErrSameAccount)
+		}
+	case TransferOutbound:
+		if snapshot.Beneficiary.Name() == "" || snapshot.TrackingKey == "" {
+			return nil, fmt.Errorf("restore transfer %s: outbound transfer without beneficiary or tracking key", snapshot.ID)
+		}
+	case TransferInbound:
+		if snapshot.TrackingKey == "" || snapshot.OperationDate.IsZero() {
+			return nil, fmt.Errorf("restore transfer %s: inbound transfer without tracking key or operation date", snapshot.ID)
+		}
+	default:
+		return nil, fmt.Errorf("restore transfer %s: unknown kind %q", snapshot.ID, snapshot.Kind)
+	}
+	if snapshot.Attempts < 0 {
+		return nil, fmt.Errorf("restore transfer %s: negative attempts %d", snapshot.ID, snapshot.Attempts)
+	}
+	transfer := &Transfer{
+		ID: snapshot.ID, Version: snapshot.Version, kind: snapshot.Kind, fromID: snapshot.FromID, toID: snapshot.ToID,
+		beneficiary: snapshot.Beneficiary, originator: snapshot.Originator, trackingKey: snapshot.TrackingKey, operationDate: snapshot.OperationDate,
+		details: snapshot.Details, amount: snapshot.Amount, creditAmount: snapshot.CreditAmount,
+		status: snapshot.Status, failureReason: snapshot.FailureReason, railReference: snapshot.RailReference, attempts: snapshot.Attempts,
+		acceptedAt: snapshot.AcceptedAt, settledAt: snapshot.SettledAt, createdAt: snapshot.CreatedAt, updatedAt: snapshot.UpdatedAt,
+	}
+	if snapshot.Quote != nil {
+		quote := *snapshot.Quote
+		transfer.quote = &quote
+	}
+	if snapshot.Rejection != nil {
+		rejection := *snapshot.Rejection
+		transfer.rejection = &rejection
+	}
+	return transfer, nil
+}
+
+// ApplyQuote converts the transfer at quote, so the destination is credited in
+// the quote's target currency. The quote must be unexpired at now.
+func (t *Transfer) ApplyQuote(quote FXQuote, now time.Time) error {
+	if t.status != TransferPending {
+		return fmt.Errorf("%w: quote on %s transfer", ErrInvalidTransition, t.status)
+	}
+	converted, err := quote.Convert(t.amount, now)
+	if err != nil {
+		return err
+	}
+	if !converted.IsPositive() {
+		return ErrInvalidAmount
+	}
+	t.creditAmount = converted
+	t.quote = &quote
+	return nil
+}
+
+// MarkSent records that an outbound transfer was handed to the payment rail.
+func (t *Transfer) MarkSent(now time.Time) error {
+	if t.kind != TransferOutbound {
+		return fmt.Errorf("%w: %s transfers are not sent to the payment rail", ErrInvalidTransition, t.kind)
+	}
+	if err := t.transition(now, TransferSent, "", TransferPending); err != nil {
+		return err
+	}
+	t.rejection = nil
+	t.attempts++
+	return nil
+}
+
+// RecordAcceptance keeps the rail's reference for an outbound transfer it
+// took. The status does not change: the rail may settle the order later.
+func (t *Transfer) RecordAcceptance(railReference string, acceptedAt time.Time) error {
+	if t.kind != TransferOutbound || t.status == TransferPending || t.status == TransferFailed {
+		return fmt.Errorf("%w: %s %s transfer was not taken by the rail", ErrInvalidTransition, t.status, t.kind)
+	}
+	t.railReference, t.acceptedAt = railReference, acceptedAt
+	return nil
+}
+
+// Requeue puts a SENT transfer back to PENDING when it never reached the rail
+// (e.g. the gateway refused to call it), so a later batch sends it again. The
+// attempt does not count.
+func (t *Transfer) Requeue(now time.Time) error {
+	if t.kind != TransferOutbound {
+		return fmt.Errorf("%w: %s transfers are not sent to the payment rail", ErrInvalidTransition, t.kind)
+	}
+	if err := t.transition(now, TransferPending, "", TransferSent); err != nil {
+		return err
+	}
+	t.attempts--
+	return nil
+}
+
+// MarkRejected records the rail's refusal of a SENT transfer: it goes back to
+// PENDING for another attempt when the rejection is retryable, and FAILED
+// otherwise.
+func (t *Transfer) MarkRejected(rejection RailRejection, now time.Time) error {
+	if t.kind != TransferOutbound {
+		return fmt.Errorf("%w: %s transfers are not sent to the payment rail", ErrInvalidTransition, t.kind)
+	}
+	var err error
+	if rejection.Retryable {
+		err = t.transition(now, TransferPending, "", TransferSent)
+	} else {
+		err = t.transition(now, TransferFailed, rejection.Code+": "+rejection.Reason, TransferSent)
+	}
+	if err != nil {
+		return err
+	}
+	t.rejection = &rejection
+	return nil
+}
+
+// MarkSettled records that the money reached the destination: straight from
+// PENDING for book and inbound transfers, after the rail accepted it for
+// outbound ones.
+func (t *Transfer) MarkSettled(now time.Time) error {
+	from := TransferPending
+	if t.kind == TransferOutbound {
+		from = TransferSent
+	}
+	if err := t.transition(now, TransferSettled, "", from); err != nil {
+		return err
+	}
+	t.settledAt = now
+	return nil
+}
+
+// MarkFailed records that the transfer did not go through.
+func (t *Transfer) MarkFailed(reason string, now time.Time) error {
+	return t.transition(now, TransferFailed, reason, TransferPending, TransferSent)
+}
+
+// MarkReversed records that a sent/settled transfer was undone.
+func (t *Transfer) MarkReversed(reason string, now time.Time) error {
+	return t.transition(now, TransferReversed, reason, TransferSent, TransferSettled)
+}
+
+// MarkReturned records that the beneficiary's bank returned an outbound transfer.
+func (t *Transfer) MarkReturned(reason string, now time.Time) error {
+	if t.kind != TransferOutbound {
+		return fmt.Errorf("%w: %s transfers cannot be returned", ErrInvalidTransition, t.kind)
+	}
+	return t.transition(now, TransferReturned, reason, TransferSent, TransferSettled)
+}
+
+func (t *Transfer) transition(now time.Time, to TransferStatus, reason string, allowedFrom ...TransferStatus) error {
+	for _, from := range allowedFrom {
+		if t.status == from {
+			t.status = to
+			t.failureReason = reason
+			t.updatedAt = now
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, t.status, to)
+}
+
+func (t *Transfer) Kind() TransferKind             { return t.kind }
+func (t *Transfer) FromID() string                 { return t.fromID }
+func (t *Transfer) ToID() string                   { return t.toID }
+func (t *Transfer) Beneficiary() Beneficiary       { return t.beneficiary }
+func (t *Transfer) Originator() Originator         { return t.originator }
+func (t *Transfer) TrackingKey() string            { return t.trackingKey }
+func (t *Transfer) OperationDate() time.Time       { return t.operationDate }
+func (t *Transfer) PaymentDetails() PaymentDetails { return t.details }
+func (t *Transfer) Amount() Money                  { return t.amount }
+func (t *Transfer) CreditAmount() Money            { return t.creditAmount }
+func (t *Transfer) Status() TransferStatus         { return t.status }
+func (t *Transfer) FailureReason() string          { return t.failureReason }
+func (t *Transfer) RailReference() string          { return t.railReference }
+func (t *Transfer) Attempts() int                  { return t.attempts }
+func (t *Transfer) AcceptedAt() time.Time          { return t.acceptedAt }
+func (t *Transfer) SettledAt() time.Time           { return t.settledAt }
+func (t *Transfer) CreatedAt() time.Time           { return t.createdAt }
+func (t *Transfer) UpdatedAt() time.Time           { return t.updatedAt }
+
+// SPEIKey identifies a SPEI transfer on the rail; ok is false for book
+// transfers.
+func (t *Transfer) SPEIKey() (key SPEIKey, ok bool) {
+	switch t.kind {
+	case TransferOutbound:
+		return OutboundSPEIKey(t.trackingKey), true
+	case TransferInbound:
+		return InboundSPEIKey(t.originator.BankCode, t.trackingKey, t.operationDate), true
+	}
+	return SPEIKey{}, false
+}
+
+// Rejection returns the rail's last refusal of an outbound transfer, if any.
+func (t *Transfer) Rejection() (RailRejection, bool) {
+	if t.rejection == nil {
+		return RailRejection{}, false
+	}
+	return *t.rejection, true
+}
+
+// Quote returns the FX quote used, if the transfer is cross-currency.
+func (t *Transfer) Quote() (FXQuote, bool) {
+	if t.quote == nil {
+		return FXQuote{}, false
+	}
+	return *t.quote, true
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransferLifecycle(t *testing.T) {
+	now := time.Now()
+	beneficiary, err := NewBeneficiary("012180000118359713", "Carol")
+	if err != nil {
+		t.Fatalf("beneficiary: %v", err)
+	}
+	transfer, err := NewOutboundTransfer("t-1", "a", beneficiary, PaymentDetails{Concept: "rent", Reference: 42}, mxn(100), now)
+	if err != nil {
+		t.Fatalf("new transfer: %v", err)
+	}
+	if err := transfer.MarkSettled(now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("PENDING cannot settle, got %v", err)
+	}
+	if err := transfer.MarkSent(now); err != nil {
+		t.Fatalf("sent: %v", err)
+	}
+	if err := transfer.MarkSettled(now); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+	if err := transfer.MarkFailed("late", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("SETTLED cannot fail, got %v", err)
+	}
+	if err := transfer.MarkReversed("chargeback", now); err != nil {
+		t.Fatalf("reversed: %v", err)
+	}
+	if transfer.Status() != TransferReversed || transfer.FailureReason() != "chargeback" {
+		t.Fatalf("unexpected state: %s %q", transfer.Status(), transfer.FailureReason())
+	}
+}
+
+func TestOutboundTransferReturned(t *testing.T) {
+	now := time.Now()
+	beneficiary, _ := NewBeneficiary("012180000118359713", "Carol")
+	transfer, _ := NewOutboundTransfer("t-1", "a", beneficiary, PaymentDetails{Concept: "rent", Reference: 42}, mxn(100), now)
+	if err := transfer.MarkReturned("cuenta inexistente", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("PENDING cannot be returned, got %v", err)
+	}
+	_ = transfer.MarkSent(now)
+	_ = transfer.MarkSettled(now)
+	if err := transfer.MarkReturned("cuenta inexistente", now); err != nil {
+		t.Fatalf("returned after settling: %v", err)
+	}
+	if err := transfer.MarkSettled(now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("RETURNED is final, got %v", err)
+	}
+	book, _ := NewTransfer("t-2", "a", "b", mxn(100), now)
+	_ = book.MarkSettled(now)
+	if err := book.MarkReturned("no", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("book transfers are never returned, got %v", err)
+	}
+}
+
+func TestBookTransferSkipsThePaymentRail(t *testing.T) {
+	now := time.Now()
+	if _, err := NewTransfer("t-0", "a", "a", mxn(100), now); !errors.Is(err, ErrSameAccount) {
+		t.Fatalf("want ErrSameAccount got %v", err)
+	}
+	transfer, _ := NewTransfer("t-1", "a", "b", mxn(100), now)
+	if err := transfer.MarkSent(now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("book transfers are never sent, got %v", err)
+	}
+	if err := transfer.MarkSettled(now); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+}
+
+func TestOutboundTransferValidation(t *testing.T) {
+	now := time.Now()
+	if _, err := NewBeneficiary("012180000118359713", "  "); !errors.Is(err, ErrInvalidBeneficiary) {
+		t.Fatalf("want ErrInvalidBeneficiary got %v", err)
+	}
+	if _, err := NewBeneficiary("012180000118359714", "Carol"); !errors.Is(err, ErrInvalidCLABEControlDigit) {
+		t.Fatalf("want ErrInvalidCLABEControlDigit got %v", err)
+	}
+	beneficiary, _ := NewBeneficiary("012180000118359713", "Carol")
+	dollars, _ := NewMoney(100, USD)
+	if _, err := NewOutboundTransfer("t-1", "a", beneficiary, PaymentDetails{Concept: "rent", Reference: 1}, dollars, now); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("SPEI is MXN only, got %v", err)
+	}
+	for _, details := range []PaymentDetails{
+		{Concept: "", Reference: 1},
+		{Concept: "a concept well beyond forty characters long", Reference: 1},
+		{Concept: "rent", Reference: 0},
+		{Concept: "rent", Reference: 10_000_000},
+	} {
+		if _, err := NewOutboundTransfer("t-1", "a", beneficiary, details, mxn(100), now); !errors.Is(err, ErrInvalidPaymentDetails) {
+			t.Errorf("%+v: want ErrInvalidPaymentDetails got %v", details, err)
+		}
+	}
+}
+
+func TestInboundTransfer(t *testing.T) {
+	now := time.Now()
+	originator := Originator{CLABE: "012180000118359713", Name: "Carol", BankCode: "012"}
+	details := PaymentDetails{Concept: "invoice 7", Reference: 7}
+	if _, err := NewInboundTransfer("t-1", "  ", now, "a", originator, details, mxn(100), now); !errors.Is(err, ErrInvalidPaymentDetails) {
+		t.Fatalf("want ErrInvalidPaymentDetails got %v", err)
+	}
+	dollars, _ := NewMoney(100, USD)
+	if _, err := NewInboundTransfer("t-1", "BBVA123", now, "a", originator, details, dollars, now); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("SPEI is MXN only, got %v", err)
+	}
+	if _, err := NewInboundTransfer("t-1", "BBVA123", time.Time{}, "a", originator, details, mxn(100), now); !errors.Is(err, ErrInvalidPaymentDetails) {
+		t.Fatalf("the operation date is required, got %v", err)
+	}
+	transfer, err := NewInboundTransfer("t-1", "BBVA123", now, "a", originator, details, mxn(100), now)
+	if err != nil {
+		t.Fatalf("new transfer: %v", err)
+	}
+	if err := transfer.MarkSent(now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("inbound transfers are never sent, got %v", err)
+	}
+	if err := transfer.MarkSettled(now); err != nil {
+		t.Fatalf("settled: %v", err)
+	}
+	if transfer.TrackingKey() != "BBVA123" || transfer.Originator().Name != "Carol" {
+		t.Fatalf("unexpected transfer: %q %+v", transfer.TrackingKey(), transfer.Originator())
+	}
+	if key, ok := transfer.SPEIKey(); !ok || key != InboundSPEIKey("012", "BBVA123", now) {
+		t.Fatalf("unexpected key %+v", key)
+	}
+}
+
+func TestOutboundTransferRejectedByRail(t *testing.T) {
+	now := time.Now()
+	beneficiary, _ := NewBeneficiary("012180000118359713", "Carol")
+	transfer, _ := NewOutboundTransfer("t-1", "a", beneficiary, PaymentDetails{Concept: "rent", Reference: 42}, mxn(100), now)
+	_ = transfer.MarkSent(now)
+
+	if err := transfer.MarkRejected(RailRejection{Code: "RAIL_UNAVAILABLE", Retryable: true, Reason: "outage"}, now); err != nil {
+		t.Fatalf("retryable rejection: %v", err)
+	}
+	if rejection, rejected := transfer.Rejection(); transfer.Status() != TransferPending || !rejected || rejection.Code != "RAIL_UNAVAILABLE" {
+		t.Fatalf("a retryable rejection waits PENDING, got %s %+v", transfer.Status(), rejection)
+	}
+
+	_ = transfer.MarkSent(now)
+	if _, rejected := transfer.Rejection(); rejected {
+		t.Fatalf("sending again clears the last rejection")
+	}
+	if err := transfer.MarkRejected(RailRejection{Code: "INVALID_ACCOUNT", Reason: "cuenta inexistente"}, now); err != nil {
+		t.Fatalf("permanent rejection: %v", err)
+	}
+	if transfer.Status() != TransferFailed || transfer.FailureReason() != "INVALID_ACCOUNT: cuenta inexistente" {
+		t.Fatalf("a permanent rejection fails, got %s %q", transfer.Status(), transfer.FailureReason())
+	}
+	if err := transfer.RecordAcceptance("3000001", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("a FAILED transfer was not taken by the rail, got %v", err)
+	}
+}
+
+func TestTransferSnapshotRoundTrip(t *testing.T) {
+	now := time.Now()
+	beneficiary, _ := NewBeneficiary("012180000118359713", "Carol")
+	transfer, _ := NewOutboundTransfer("t-1", "a", beneficiary, PaymentDetails{Concept: "rent", Reference: 42}, mxn(100), now)
+	_ = transfer.MarkSent(now)
+	_ = transfer.MarkRejected(RailRejection{Code: "RAIL_UNAVAILABLE", Retryable: true, Reason: "busy"}, now)
+	transfer.Version = 3
+
+	restored, err := RestoreTransfer(transfer.Snapshot())
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	rejection, rejected := restored.Rejection()
+	if restored.Version != 3 || restored.Status() != TransferPending || restored.Attempts() != 1 || !rejected || rejection.Code != "RAIL_UNAVAILABLE" {
+		t.Fatalf("round trip lost state: %+v", restored.Snapshot())
+	}
+	if restored.Beneficiary() != beneficiary || restored.TrackingKey() != "t-1" {
+		t.Fatalf("round trip lost the beneficiary: %+v", restored.Snapshot())
+	}
+
+	corrupt := transfer.Snapshot()
+	corrupt.Status = "LOST"
+	if _, err := RestoreTransfer(corrupt); err == nil {
+		t.Fatalf("unknown status must not restore")
+	}
+	corrupt = transfer.Snapshot()
+	corrupt.Beneficiary = Beneficiary{}
+	if _, err := RestoreTransfer(corrupt); err == nil {
+		t.Fatalf("outbound transfer without beneficiary must not restore")
+	}
+}
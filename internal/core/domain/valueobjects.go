@@ -2,12 +2,22 @@ package domain
 
 import "strings"
 
-// CLABE is a simple Value Object enforcing the 18-digit rule.
+// clabeWeights are Banxico's weights for the first 17 CLABE digits (3-7-1 repeated).
+var clabeWeights = [17]int{3, 7, 1, 3, 7, 1, 3, 7, 1, 3, 7, 1, 3, 7, 1, 3, 7}
+
+// CLABE is a Value Object for the 18-digit Mexican interbank account number:
+//
+//	BBB PPP CCCCCCCCCCC K
+//	 │   │       │      └─ control digit (weighted 3-7-1, mod 10)
+//	 │   │       └──────── account number
+//	 │   └──────────────── plaza (city) code
+//	 └──────────────────── bank code (participant catalog)
 type CLABE struct {
 	value string
 }
 
-// NewCLABE validates and normalizes a CLABE string.
+// NewCLABE normalizes a CLABE string and validates its length, digits,
+// control digit and bank code.
 func NewCLABE(raw string) (CLABE, error) {
 	normalized := strings.ReplaceAll(raw, " ", "")
 	if len(normalized) != 18 {
@@ -18,7 +28,32 @@ func NewCLABE(raw string) (CLABE, error) {
 			return CLABE{}, ErrInvalidCLABE
 		}
 	}
+	if clabeControlDigit(normalized[:17]) != normalized[17] {
+		return CLABE{}, ErrInvalidCLABEControlDigit
+	}
+	if _, known := participantBanks[normalized[:3]]; !known {
+		return CLABE{}, ErrUnknownBank
+	}
 	return CLABE{value: normalized}, nil
 }
 
+// clabeControlDigit computes the control digit: each digit times its weight
+// (mod 10) is summed, and the digit is (10 - sum mod 10) mod 10.
+func clabeControlDigit(first17 string) byte {
+	sum := 0
+	for index := range clabeWeights {
+		sum += (int(first17[index]-'0') * clabeWeights[index]) % 10
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
 func (c CLABE) String() string { return c.value }
+
+// BankCode is the 3-digit participant code (e.g. "012").
+func (c CLABE) BankCode() string { return c.value[:3] }
+
+// BankName is the participant's short name from the catalog.
+func (c CLABE) BankName() string { return participantBanks[c.BankCode()] }
+
+// Plaza is the 3-digit plaza (city) code where the account was opened.
+func (c CLABE) Plaza() string { return c.value[3:6] }
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCLABEValidation(t *testing.T) {
+	clabe, err := NewCLABE("032 180 00011835971 9")
+	if err != nil {
+		t.Fatalf("valid clabe: %v", err)
+	}
+	if clabe.BankCode() != "032" || clabe.BankName() != "IXE" || clabe.Plaza() != "180" {
+		t.Fatalf("unexpected decoding: %s %s %s", clabe.BankCode(), clabe.BankName(), clabe.Plaza())
+	}
+
+	cases := map[string]error{
+		"03218000011835971":  ErrInvalidCLABE,             // 17 digits
+		"03218000011835971X": ErrInvalidCLABE,             // not a digit
+		"032180000118359718": ErrInvalidCLABEControlDigit, // typo in control digit
+		"032180000118359791": ErrInvalidCLABEControlDigit, // swapped digits
+		"999180000118359713": ErrUnknownBank,              // valid control digit, no such bank
+	}
+	for raw, want := range cases {
+		if _, err := NewCLABE(raw); !errors.Is(err, want) {
+			t.Errorf("%s: want %v got %v", raw, want, err)
+		}
+	}
+}
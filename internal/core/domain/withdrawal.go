@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithdrawalChannel is where cash leaves the bank.
+type WithdrawalChannel string
+
+const (
+	ChannelATM    WithdrawalChannel = "ATM"
+	ChannelBranch WithdrawalChannel = "BRANCH"
+	ChannelCard   WithdrawalChannel = "CARD"
+)
+
+// withdrawalLimits caps a single withdrawal per channel and currency, in minor
+// units. A currency missing from a channel cannot be withdrawn through it.
+var withdrawalLimits = map[WithdrawalChannel]map[Currency]int64{
+	ChannelATM:    {MXN: 9_000_00, USD: 500_00, EUR: 450_00},
+	ChannelCard:   {MXN: 30_000_00, USD: 1_500_00, EUR: 1_400_00},
+	ChannelBranch: {MXN: 250_000_00, USD: 12_500_00, EUR: 11_500_00},
+}
+
+// ParseWithdrawalChannel validates a channel name (case-insensitive).
+func ParseWithdrawalChannel(raw string) (WithdrawalChannel, error) {
+	channel := WithdrawalChannel(strings.ToUpper(strings.TrimSpace(raw)))
+	if _, known := withdrawalLimits[channel]; !known {
+		return "", fmt.Errorf("%w: %q", ErrUnknownWithdrawalChannel, raw)
+	}
+	return channel, nil
+}
+
+// Limit is the largest single withdrawal in currency allowed through the
+// channel; ok is false when the channel pays out no cash in that currency.
+func (c WithdrawalChannel) Limit(currency Currency) (limit Money, ok bool) {
+	amount, ok := withdrawalLimits[c][currency]
+	return Money{amount: amount, currency: currency}, ok
+}
+
+// CheckLimit rejects amounts above the channel's per-operation limit for
+// their currency, and currencies the channel has no limit for.
+func (c WithdrawalChannel) CheckLimit(amount Money) error {
+	limit, ok := c.Limit(amount.Currency())
+	if !ok {
+		return fmt.Errorf("%w: %s does not pay out %s", ErrUnsupportedCurrency, c, amount.Currency())
+	}
+	if amount.Amount() > limit.Amount() {
+		return fmt.Errorf("%w: %s allows up to %s", ErrWithdrawalLimitExceeded, c, limit)
+	}
+	return nil
+}
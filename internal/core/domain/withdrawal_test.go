@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithdrawalLimitsDependOnCurrency(t *testing.T) {
+	cases := []struct {
+		amount  Money
+		wantErr error
+	}{
+		{Money{amount: 9_000_00, currency: MXN}, nil},
+		{Money{amount: 500_00, currency: USD}, nil},
+		{Money{amount: 500_01, currency: USD}, ErrWithdrawalLimitExceeded},
+		{Money{amount: 9_000_00, currency: USD}, ErrWithdrawalLimitExceeded},
+		{Money{amount: 100, currency: "JPY"}, ErrUnsupportedCurrency},
+	}
+	for _, tc := range cases {
+		if err := ChannelATM.CheckLimit(tc.amount); !errors.Is(err, tc.wantErr) {
+			t.Errorf("ATM %s: want %v got %v", tc.amount, tc.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package backoff
+
+import "sync"
+
+// Budget caps retries across calls to a share of first attempts, so a
+// struggling dependency is not flooded with retries (retry storm).
+// Every call earns ratio tokens, up to maxTokens; every retry spends one.
+// A Budget is safe for concurrent use and is meant to be shared.
+type Budget struct {
+	mutex     sync.Mutex
+	tokens    float64
+	ratio     float64
+	maxTokens float64
+}
+
+// NewBudget allows about ratio retries per call (e.g. 0.2) on average, and
+// bursts of up to maxTokens retries. It starts full.
+func NewBudget(ratio, maxTokens float64) *Budget {
+	return &Budget{tokens: maxTokens, ratio: ratio, maxTokens: maxTokens}
+}
+
+func (budget *Budget) deposit() {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	budget.tokens = min(budget.maxTokens, budget.tokens+budget.ratio)
+}
+
+func (budget *Budget) withdraw() bool {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	if budget.tokens < 1 {
+		return false
+	}
+	budget.tokens--
+	return true
+}
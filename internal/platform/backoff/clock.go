@@ -0,0 +1,32 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells time and waits. Tests inject one that does not really sleep.
+type Clock interface {
+	Now() time.Time
+	// Sleep waits for duration, returning early with ctx.Err() if ctx ends.
+	Sleep(ctx context.Context, duration time.Duration) error
+}
+
+// SystemClock is the real wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+func (SystemClock) Sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,7 +1,6 @@
 package backoff
 
 import (
-	"math"
 	"math/rand"
 	"time"
 )
@@ -9,10 +8,11 @@ import (
 // FullJitter computes an exponential backoff with full jitter.
 // attemptIndex starts at 0. maxDelay is the maximum sleep.
 func FullJitter(attemptIndex int, baseDelay time.Duration, multiplier float64, maxDelay time.Duration) time.Duration {
-	exponential := time.Duration(float64(baseDelay) * math.Pow(multiplier, float64(attemptIndex)))
-	if exponential > maxDelay {
-		exponential = maxDelay
-	}
-	randomNanos := rand.Int63n(int64(exponential) + 1) // [0, exponential]
-	return time.Duration(randomNanos)
+	return time.Duration(rand.Int63n(int64(exponential(attemptIndex, baseDelay, multiplier, maxDelay)) + 1)) // [0, exponential]
+}
+
+// FullJitterWith is FullJitter drawing from random, so that a seeded source
+// gives reproducible delays. random must not be shared across goroutines.
+func FullJitterWith(random *rand.Rand, attemptIndex int, baseDelay time.Duration, multiplier float64, maxDelay time.Duration) time.Duration {
+	return time.Duration(random.Int63n(int64(exponential(attemptIndex, baseDelay, multiplier, maxDelay)) + 1))
 }
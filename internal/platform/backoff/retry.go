@@ -0,0 +1,105 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is joined to the last error when a call stops retrying
+// because its Budget or MaxElapsed ran out.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Classifier reports whether err is worth retrying.
+type Classifier func(err error) bool
+
+// RetryAll retries every error but a cancelled or expired context.
+func RetryAll(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Policy describes how a Retrier retries. Zero fields take the defaults
+// documented on each one.
+type Policy struct {
+	MaxAttempts int           // including the first; default 1 (no retries)
+	Strategy    Strategy      // default FullJitterBackoff(100ms, 2, 5s)
+	Classifier  Classifier    // default RetryAll
+	Budget      *Budget       // optional, shared across calls
+	MaxElapsed  time.Duration // per call, waits included; 0 means no limit
+	Clock       Clock         // default SystemClock
+	Random      *rand.Rand    // default seeded from the clock
+	// OnRetry is told about every retry before waiting delay, e.g. to log it.
+	OnRetry func(attemptIndex int, delay time.Duration, err error)
+}
+
+// Retrier runs operations under a Policy. It is safe for concurrent use.
+type Retrier struct {
+	policy Policy
+	mutex  sync.Mutex // guards policy.Random
+}
+
+func NewRetrier(policy Policy) *Retrier {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Strategy == nil {
+		policy.Strategy = FullJitterBackoff(100*time.Millisecond, 2, 5*time.Second)
+	}
+	if policy.Classifier == nil {
+		policy.Classifier = RetryAll
+	}
+	if policy.Clock == nil {
+		policy.Clock = SystemClock{}
+	}
+	if policy.Random == nil {
+		policy.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &Retrier{policy: policy}
+}
+
+// Do runs operation until it succeeds, fails with an error the Classifier
+// does not retry, or the policy gives up; it then returns the last error.
+func (retrier *Retrier) Do(ctx context.Context, operation func(ctx context.Context) error) error {
+	_, err := Retry(ctx, retrier, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, operation(ctx)
+	})
+	return err
+}
+
+// Retry is Do for operations that return a value.
+func Retry[T any](ctx context.Context, retrier *Retrier, operation func(ctx context.Context) (T, error)) (T, error) {
+	policy := retrier.policy
+	if policy.Budget != nil {
+		policy.Budget.deposit()
+	}
+	startedAt := policy.Clock.Now()
+	var delay time.Duration
+	for attemptIndex := 0; ; attemptIndex++ {
+		value, err := operation(ctx)
+		if err == nil || !policy.Classifier(err) || attemptIndex+1 >= policy.MaxAttempts {
+			return value, err
+		}
+		delay = retrier.nextDelay(attemptIndex, delay)
+		if policy.MaxElapsed > 0 && policy.Clock.Now().Add(delay).Sub(startedAt) > policy.MaxElapsed {
+			return value, fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+		}
+		if policy.Budget != nil && !policy.Budget.withdraw() {
+			return value, fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attemptIndex, delay, err)
+		}
+		if err := policy.Clock.Sleep(ctx, delay); err != nil {
+			return value, err
+		}
+	}
+}
+
+func (retrier *Retrier) nextDelay(attemptIndex int, previous time.Duration) time.Duration {
+	retrier.mutex.Lock()
+	defer retrier.mutex.Unlock()
+	return retrier.policy.Strategy(attemptIndex, previous, retrier.policy.Random)
+}
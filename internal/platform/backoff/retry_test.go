@@ -0,0 +1,103 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// fakeClock advances when slept on instead of waiting.
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (clock *fakeClock) Now() time.Time { return clock.now }
+
+func (clock *fakeClock) Sleep(ctx context.Context, duration time.Duration) error {
+	clock.slept = append(clock.slept, duration)
+	clock.now = clock.now.Add(duration)
+	return ctx.Err()
+}
+
+var errTransient = errors.New("transient")
+
+// failing fails the first failures calls with err.
+func failing(failures int, err error) (func(ctx context.Context) (int, error), *int) {
+	calls := 0
+	return func(ctx context.Context) (int, error) {
+		calls++
+		if calls <= failures {
+			return 0, err
+		}
+		return calls, nil
+	}, &calls
+}
+
+func newTestRetrier(clock *fakeClock, policy Policy) *Retrier {
+	policy.Clock = clock
+	policy.Random = rand.New(rand.NewSource(1))
+	if policy.Strategy == nil {
+		policy.Strategy = ConstantBackoff(time.Second)
+	}
+	return NewRetrier(policy)
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	clock := &fakeClock{}
+	operation, calls := failing(2, errTransient)
+	value, err := Retry(context.Background(), newTestRetrier(clock, Policy{MaxAttempts: 3}), operation)
+	if err != nil || value != 3 || *calls != 3 {
+		t.Fatalf("want success on the third call, got value=%d err=%v calls=%d", value, err, *calls)
+	}
+	if len(clock.slept) != 2 {
+		t.Fatalf("want 2 waits got %v", clock.slept)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	clock := &fakeClock{}
+	operation, calls := failing(5, errTransient)
+	if _, err := Retry(context.Background(), newTestRetrier(clock, Policy{MaxAttempts: 3}), operation); !errors.Is(err, errTransient) || *calls != 3 {
+		t.Fatalf("want the last error after 3 calls, got %v after %d", err, *calls)
+	}
+
+	permanent := errors.New("permanent")
+	operation, calls = failing(5, permanent)
+	classifier := func(err error) bool { return !errors.Is(err, permanent) }
+	if _, err := Retry(context.Background(), newTestRetrier(clock, Policy{MaxAttempts: 3, Classifier: classifier}), operation); !errors.Is(err, permanent) || *calls != 1 {
+		t.Fatalf("permanent errors are not retried, got %v after %d calls", err, *calls)
+	}
+}
+
+func TestRetryBudgets(t *testing.T) {
+	clock := &fakeClock{}
+	operation, calls := failing(5, errTransient)
+	retrier := newTestRetrier(clock, Policy{MaxAttempts: 10, MaxElapsed: 2500 * time.Millisecond})
+	if _, err := Retry(context.Background(), retrier, operation); !errors.Is(err, ErrBudgetExhausted) || !errors.Is(err, errTransient) || *calls != 3 {
+		t.Fatalf("MaxElapsed allows two 1s waits, got %v after %d calls", err, *calls)
+	}
+
+	// Each call earns half a retry; the budget starts with one.
+	retrier = newTestRetrier(clock, Policy{MaxAttempts: 10, Budget: NewBudget(0.5, 1)})
+	operation, calls = failing(5, errTransient)
+	if _, err := Retry(context.Background(), retrier, operation); !errors.Is(err, ErrBudgetExhausted) || *calls != 2 {
+		t.Fatalf("want a single retry, got %v after %d calls", err, *calls)
+	}
+	operation, calls = failing(5, errTransient)
+	if _, err := Retry(context.Background(), retrier, operation); !errors.Is(err, ErrBudgetExhausted) || *calls != 1 {
+		t.Fatalf("the budget is shared across calls, got %v after %d calls", err, *calls)
+	}
+}
+
+func TestRetryStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := &fakeClock{}
+	retrier := newTestRetrier(clock, Policy{MaxAttempts: 5, OnRetry: func(int, time.Duration, error) { cancel() }})
+	err := retrier.Do(ctx, func(ctx context.Context) error { return errTransient })
+	if !errors.Is(err, context.Canceled) || len(clock.slept) != 1 {
+		t.Fatalf("want context.Canceled after one wait, got %v (%v)", err, clock.slept)
+	}
+}
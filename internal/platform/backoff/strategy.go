@@ -0,0 +1,56 @@
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Strategy returns how long to wait before retry attemptIndex (0 for the
+// first retry). previous is the delay it returned last time (0 at first);
+// random is the Retrier's source of randomness.
+type Strategy func(attemptIndex int, previous time.Duration, random *rand.Rand) time.Duration
+
+// ConstantBackoff always waits delay.
+func ConstantBackoff(delay time.Duration) Strategy {
+	return func(int, time.Duration, *rand.Rand) time.Duration { return delay }
+}
+
+// FullJitterBackoff waits a random time in [0, exponential]: the best spread
+// of concurrent retries, at the cost of sometimes retrying at once.
+func FullJitterBackoff(baseDelay time.Duration, multiplier float64, maxDelay time.Duration) Strategy {
+	return func(attemptIndex int, previous time.Duration, random *rand.Rand) time.Duration {
+		return FullJitterWith(random, attemptIndex, baseDelay, multiplier, maxDelay)
+	}
+}
+
+// EqualJitterBackoff waits half the exponential delay plus a random time up
+// to the other half, so it never retries at once.
+func EqualJitterBackoff(baseDelay time.Duration, multiplier float64, maxDelay time.Duration) Strategy {
+	return func(attemptIndex int, previous time.Duration, random *rand.Rand) time.Duration {
+		half := exponential(attemptIndex, baseDelay, multiplier, maxDelay) / 2
+		return half + time.Duration(random.Int63n(int64(half)+1))
+	}
+}
+
+// DecorrelatedJitterBackoff waits a random time between baseDelay and three
+// times the previous delay (capped at maxDelay): it grows like an exponential
+// backoff but each delay builds on the last one actually used.
+func DecorrelatedJitterBackoff(baseDelay, maxDelay time.Duration) Strategy {
+	return func(attemptIndex int, previous time.Duration, random *rand.Rand) time.Duration {
+		previous = max(previous, baseDelay)
+		upper := min(3*previous, maxDelay)
+		if upper <= baseDelay {
+			return upper
+		}
+		return baseDelay + time.Duration(random.Int63n(int64(upper-baseDelay)+1))
+	}
+}
+
+func exponential(attemptIndex int, baseDelay time.Duration, multiplier float64, maxDelay time.Duration) time.Duration {
+	delay := float64(baseDelay) * math.Pow(multiplier, float64(attemptIndex))
+	if delay > float64(maxDelay) {
+		return maxDelay
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,38 @@
+package backoff
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestStrategiesStayWithinBounds(t *testing.T) {
+	const (
+		base     = 100 * time.Millisecond
+		maxDelay = time.Second
+	)
+	random := rand.New(rand.NewSource(1))
+	for attemptIndex := range 10 {
+		exponential := min(base<<attemptIndex, maxDelay)
+
+		if delay := FullJitterBackoff(base, 2, maxDelay)(attemptIndex, 0, random); delay < 0 || delay > exponential {
+			t.Errorf("full jitter #%d: %s not in [0, %s]", attemptIndex, delay, exponential)
+		}
+		if delay := EqualJitterBackoff(base, 2, maxDelay)(attemptIndex, 0, random); delay < exponential/2 || delay > exponential {
+			t.Errorf("equal jitter #%d: %s not in [%s, %s]", attemptIndex, delay, exponential/2, exponential)
+		}
+		if delay := ConstantBackoff(base)(attemptIndex, 0, random); delay != base {
+			t.Errorf("constant #%d: %s", attemptIndex, delay)
+		}
+	}
+
+	decorrelated := DecorrelatedJitterBackoff(base, maxDelay)
+	var previous time.Duration
+	for attemptIndex := range 10 {
+		delay := decorrelated(attemptIndex, previous, random)
+		if delay < base || delay > min(3*max(previous, base), maxDelay) {
+			t.Errorf("decorrelated #%d: %s out of range after %s", attemptIndex, delay, previous)
+		}
+		previous = delay
+	}
+}
@@ -0,0 +1,212 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagonal-bank/internal/platform/backoff"
+	"sync"
+	"time"
+)
+
+// State of a Breaker:
+//
+//	CLOSED --FailureThreshold failures in a row--> OPEN
+//	OPEN --CoolDown elapsed--> HALF_OPEN (one trial call at a time)
+//	HALF_OPEN --trial fails--> OPEN
+//	HALF_OPEN --SuccessThreshold trials succeed--> CLOSED
+type State string
+
+const (
+	Closed   State = "CLOSED"
+	Open     State = "OPEN"
+	HalfOpen State = "HALF_OPEN"
+)
+
+// ErrOpen matches the *OpenError returned for calls the breaker refused.
+var ErrOpen = errors.New("circuit breaker open")
+
+// OpenError is returned instead of calling through. RetryAfter is the time
+// left until the breaker lets a trial call through (zero when a trial is
+// already in flight).
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrOpen, e.RetryAfter)
+}
+
+func (e *OpenError) Is(target error) bool { return target == ErrOpen }
+
+type Settings struct {
+	FailureThreshold int           // consecutive failures that open the circuit
+	CoolDown         time.Duration // time open before a trial call
+	SuccessThreshold int           // trial successes in a row that close it again
+	// IsFailure decides which errors count against the dependency; nil counts
+	// them all. A cancelled context never counts.
+	IsFailure func(err error) bool
+	Clock     backoff.Clock
+	// OnStateChange is called with the breaker locked: it must not call back
+	// into the breaker.
+	OnStateChange func(from, to State)
+}
+
+// Snapshot is what a Breaker looks like at one instant.
+type Snapshot struct {
+	State               State
+	ConsecutiveFailures int
+	RetryAfter          time.Duration // until a trial call, while Open
+}
+
+// Breaker stops calling a dependency that keeps failing, so callers fail fast
+// instead of waiting on it, and probes it again after a cool-down.
+type Breaker struct {
+	settings Settings
+
+	mutex         sync.Mutex
+	state         State
+	failures      int // consecutive, while Closed
+	successes     int // consecutive trial successes, while HalfOpen
+	openUntil     time.Time
+	trialInFlight bool
+	generation    int // bumped on every state change; outcomes of older calls are ignored
+}
+
+// New builds a Closed breaker. Zero settings default to 5 failures, a 30s
+// cool-down and 1 trial success.
+func New(settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 30 * time.Second
+	}
+	if settings.SuccessThreshold <= 0 {
+		settings.SuccessThreshold = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool { return true }
+	}
+	if settings.Clock == nil {
+		settings.Clock = backoff.SystemClock{}
+	}
+	return &Breaker{settings: settings, state: Closed}
+}
+
+// Call runs fn unless the circuit is open, in which case it returns an
+// *OpenError without calling fn. fn's error is returned as-is; a panic in fn
+// counts as a failure and is re-raised.
+func (breaker *Breaker) Call(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	generation, err := breaker.allow()
+	if err != nil {
+		return err
+	}
+	returned := false
+	defer func() {
+		switch {
+		case !returned:
+			breaker.record(generation, true)
+		case errors.Is(err, context.Canceled):
+			breaker.release(generation) // says nothing about the dependency
+		default:
+			breaker.record(generation, err != nil && breaker.settings.IsFailure(err))
+		}
+	}()
+	err = fn(ctx)
+	returned = true
+	return err
+}
+
+// Snapshot reports the current state, moving an Open breaker whose cool-down
+// is over to HalfOpen.
+func (breaker *Breaker) Snapshot() Snapshot {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	now := breaker.settings.Clock.Now()
+	breaker.coolDown(now)
+	snapshot := Snapshot{State: breaker.state, ConsecutiveFailures: breaker.failures}
+	if breaker.state == Open {
+		snapshot.RetryAfter = breaker.openUntil.Sub(now)
+	}
+	return snapshot
+}
+
+func (breaker *Breaker) allow() (int, error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	now := breaker.settings.Clock.Now()
+	breaker.coolDown(now)
+	switch breaker.state {
+	case Open:
+		return 0, &OpenError{RetryAfter: breaker.openUntil.Sub(now)}
+	case HalfOpen:
+		if breaker.trialInFlight {
+			return 0, &OpenError{}
+		}
+		breaker.trialInFlight = true
+	}
+	return breaker.generation, nil
+}
+
+func (breaker *Breaker) record(generation int, failed bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	if generation != breaker.generation {
+		return // started before the last state change
+	}
+	switch breaker.state {
+	case Closed:
+		if !failed {
+			breaker.failures = 0
+			return
+		}
+		breaker.failures++
+		if breaker.failures >= breaker.settings.FailureThreshold {
+			breaker.open()
+		}
+	case HalfOpen:
+		breaker.trialInFlight = false
+		if failed {
+			breaker.open()
+			return
+		}
+		breaker.successes++
+		if breaker.successes >= breaker.settings.SuccessThreshold {
+			breaker.transition(Closed)
+		}
+	}
+}
+
+func (breaker *Breaker) release(generation int) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	if generation == breaker.generation {
+		breaker.trialInFlight = false
+	}
+}
+
+func (breaker *Breaker) coolDown(now time.Time) {
+	if breaker.state == Open && !now.Before(breaker.openUntil) {
+		breaker.transition(HalfOpen)
+	}
+}
+
+func (breaker *Breaker) open() {
+	breaker.transition(Open)
+	breaker.openUntil = breaker.settings.Clock.Now().Add(breaker.settings.CoolDown)
+}
+
+func (breaker *Breaker) transition(to State) {
+	from := breaker.state
+	breaker.state = to
+	breaker.generation++
+	breaker.successes = 0
+	breaker.trialInFlight = false
+	if to == Closed {
+		breaker.failures = 0
+	}
+	if breaker.settings.OnStateChange != nil {
+		breaker.settings.OnStateChange(from, to)
+	}
+}
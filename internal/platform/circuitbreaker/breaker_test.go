@@ -0,0 +1,139 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// manualClock only moves when the test says so.
+type manualClock struct{ now time.Time }
+
+func (clock *manualClock) Now() time.Time { return clock.now }
+
+func (clock *manualClock) Sleep(ctx context.Context, duration time.Duration) error {
+	clock.now = clock.now.Add(duration)
+	return ctx.Err()
+}
+
+var errDown = errors.New("down")
+
+func call(breaker *Breaker, err error) (called bool, result error) {
+	result = breaker.Call(context.Background(), func(ctx context.Context) error {
+		called = true
+		return err
+	})
+	return called, result
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	clock := &manualClock{}
+	var changes []State
+	breaker := New(Settings{
+		FailureThreshold: 3, CoolDown: 10 * time.Second, Clock: clock,
+		OnStateChange: func(from, to State) { changes = append(changes, to) },
+	})
+
+	call(breaker, errDown)
+	call(breaker, errDown)
+	call(breaker, nil) // a success resets the count
+	for range 3 {
+		if called, err := call(breaker, errDown); !called || !errors.Is(err, errDown) {
+			t.Fatalf("closed breaker must call through, got called=%v err=%v", called, err)
+		}
+	}
+	if snapshot := breaker.Snapshot(); snapshot.State != Open || snapshot.RetryAfter != 10*time.Second {
+		t.Fatalf("want OPEN for 10s got %+v", snapshot)
+	}
+
+	clock.now = clock.now.Add(4 * time.Second)
+	called, err := call(breaker, nil)
+	var open *OpenError
+	if called || !errors.As(err, &open) || open.RetryAfter != 6*time.Second || !errors.Is(err, ErrOpen) {
+		t.Fatalf("open breaker must fail fast with 6s left, got called=%v err=%v", called, err)
+	}
+	if len(changes) != 1 || changes[0] != Open {
+		t.Fatalf("want one change to OPEN got %v", changes)
+	}
+}
+
+func TestBreakerProbesAfterCoolDown(t *testing.T) {
+	clock := &manualClock{}
+	breaker := New(Settings{FailureThreshold: 1, CoolDown: time.Second, SuccessThreshold: 2, Clock: clock})
+	call(breaker, errDown)
+
+	clock.now = clock.now.Add(time.Second)
+	if snapshot := breaker.Snapshot(); snapshot.State != HalfOpen {
+		t.Fatalf("want HALF_OPEN after the cool-down got %+v", snapshot)
+	}
+	if called, _ := call(breaker, errDown); !called {
+		t.Fatal("half-open breaker must let a trial through")
+	}
+	if snapshot := breaker.Snapshot(); snapshot.State != Open {
+		t.Fatalf("a failed trial reopens, got %+v", snapshot)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	call(breaker, nil)
+	if snapshot := breaker.Snapshot(); snapshot.State != HalfOpen {
+		t.Fatalf("one success out of two keeps it HALF_OPEN, got %+v", snapshot)
+	}
+	call(breaker, nil)
+	if snapshot := breaker.Snapshot(); snapshot.State != Closed {
+		t.Fatalf("want CLOSED after two successful trials got %+v", snapshot)
+	}
+}
+
+func TestBreakerAllowsOneTrialAtATime(t *testing.T) {
+	clock := &manualClock{}
+	breaker := New(Settings{FailureThreshold: 1, CoolDown: time.Second, Clock: clock})
+	call(breaker, errDown)
+	clock.now = clock.now.Add(time.Second)
+
+	var concurrent error
+	breaker.Call(context.Background(), func(ctx context.Context) error {
+		_, concurrent = call(breaker, nil)
+		return nil
+	})
+	if !errors.Is(concurrent, ErrOpen) {
+		t.Fatalf("a second call during the trial must be refused, got %v", concurrent)
+	}
+	if snapshot := breaker.Snapshot(); snapshot.State != Closed {
+		t.Fatalf("want CLOSED after the trial got %+v", snapshot)
+	}
+}
+
+func TestBreakerIgnoresErrorsThatAreNotFailures(t *testing.T) {
+	rejected := errors.New("rejected")
+	breaker := New(Settings{FailureThreshold: 1, IsFailure: func(err error) bool { return !errors.Is(err, rejected) }})
+	call(breaker, rejected)
+	call(breaker, context.Canceled)
+	if snapshot := breaker.Snapshot(); snapshot.State != Closed || snapshot.ConsecutiveFailures != 0 {
+		t.Fatalf("want CLOSED without failures got %+v", snapshot)
+	}
+}
+
+func TestBreakerCountsAPanickingTrialAsAFailure(t *testing.T) {
+	clock := &manualClock{}
+	breaker := New(Settings{FailureThreshold: 1, CoolDown: time.Second, Clock: clock})
+	call(breaker, errDown)
+	clock.now = clock.now.Add(time.Second)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the panic must reach the caller")
+			}
+		}()
+		breaker.Call(context.Background(), func(ctx context.Context) error { panic("boom") })
+	}()
+	if snapshot := breaker.Snapshot(); snapshot.State != Open || snapshot.RetryAfter != time.Second {
+		t.Fatalf("a panicking trial reopens, got %+v", snapshot)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if called, err := call(breaker, nil); !called || err != nil {
+		t.Fatalf("the next trial must go through, got called=%v err=%v", called, err)
+	}
+}